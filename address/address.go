@@ -0,0 +1,141 @@
+// Package address validates cryptocurrency address formats before withdrawal:
+// Base58Check for legacy BTC/LTC/DOGE addresses, Bech32/Bech32m for segwit,
+// EIP-55 for ETH and ERC-20 tokens, and memo/tag presence for coins that need one.
+package address
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+// Validator checks an address of a single coin
+type Validator interface {
+	Validate(address string) error
+}
+
+// AnyOf accepts an address accepted by any of the validators. If none accepts
+// it, the first error that is not ErrFormat is returned, as it comes from the
+// validator that recognised the address format.
+type AnyOf []Validator
+
+// Validate checks the address
+func (v AnyOf) Validate(address string) error {
+	var first error
+	for _, validator := range v {
+		err := validator.Validate(address)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, ErrFormat) {
+			return err
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Memo validates addresses of coins that take a memo or destination tag after
+// Separator (example: "rAddress:12345")
+type Memo struct {
+	Address   Validator // validator of the address part, may be nil
+	Separator string    // separator of the address and the memo (on default: ":")
+	Required  bool      // reject addresses without a memo
+}
+
+// Validate checks the address
+func (v Memo) Validate(address string) error {
+	separator := v.Separator
+	if separator == "" {
+		separator = ":"
+	}
+
+	addr, memo := address, ""
+	if i := strings.LastIndex(address, separator); i >= 0 {
+		addr, memo = address[:i], address[i+len(separator):]
+	}
+	if addr == "" {
+		return fail(ErrFormat, "empty address")
+	}
+	if v.Required && memo == "" {
+		return fail(ErrMemoRequired, "")
+	}
+	if v.Address != nil {
+		return v.Address.Validate(addr)
+	}
+
+	return nil
+}
+
+// Registry holds validators by coin and implements api.AddressValidator. Addresses
+// of coins without a registered validator are rejected unless Lenient is set.
+type Registry struct {
+	mu         sync.RWMutex
+	validators map[string]Validator
+
+	// Lenient accepts addresses of coins without a registered validator
+	Lenient bool
+}
+
+// NewRegistry is a constructor for the Registry with validators of common coins
+func NewRegistry() *Registry {
+	r := &Registry{
+		validators: make(map[string]Validator),
+	}
+
+	r.Register("btc", AnyOf{Base58Check{Versions: []byte{0x00, 0x05}}, Bech32{HRP: "bc"}})
+	r.Register("ltc", AnyOf{Base58Check{Versions: []byte{0x30, 0x32, 0x05}}, Bech32{HRP: "ltc"}})
+	r.Register("doge", Base58Check{Versions: []byte{0x1e, 0x16}})
+	r.Register("dash", Base58Check{Versions: []byte{0x4c, 0x10}})
+	r.Register("eth", EIP55{})
+	r.Register("etc", EIP55{})
+	r.Register("xrp", Memo{Required: true})
+	r.Register("xlm", Memo{Required: true})
+	r.Register("eos", Memo{Required: true})
+
+	return r
+}
+
+// Register sets the validator of the coin
+func (r *Registry) Register(coin string, v Validator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.validators[strings.ToLower(coin)] = v
+}
+
+// RegisterERC20 registers EIP-55 validation for ERC-20 tokens (example: usdt)
+func (r *Registry) RegisterERC20(coins ...string) {
+	for _, coin := range coins {
+		r.Register(coin, EIP55{})
+	}
+}
+
+// ValidateAddress checks the address of the coin and returns an *Error if it is invalid
+func (r *Registry) ValidateAddress(coin, address string) error {
+	r.mu.RLock()
+	v, ok := r.validators[strings.ToLower(coin)]
+	r.mu.RUnlock()
+
+	if !ok {
+		if r.Lenient {
+			return nil
+		}
+		return &Error{Coin: coin, Address: address, Reason: ErrUnknownCoin}
+	}
+
+	err := v.Validate(address)
+	if err == nil {
+		return nil
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		e.Coin, e.Address = coin, address
+		return e
+	}
+
+	return &Error{Coin: coin, Address: address, Reason: ErrFormat, Detail: err.Error()}
+}
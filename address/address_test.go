@@ -0,0 +1,98 @@
+package address
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBech32(t *testing.T) {
+	// vectors of BIP-173 and BIP-350
+	tests := []struct {
+		hrp     string
+		address string
+		err     error
+	}{
+		{"bc", "BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", nil},
+		{"tb", "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", nil},
+		{"bc", "bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7kt5nd6y", nil},
+		{"bc", "BC1SW50QGDZ25J", nil},
+		{"bc", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", nil},
+
+		{"bc", "tc1qw508d6qejxtdg4y5r3zarvary0c5xw7kg3g4ty", ErrNetwork},
+		{"bc", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", ErrChecksum},
+		{"bc", "BC13W508D6QEJXTDG4Y5R3ZARVARY0C5XW7KN40WF2", ErrVersion},
+		{"tb", "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sL5k7", ErrFormat},
+		{"bc", "bc1gmk9yu", ErrFormat},
+		// witness version 1 and later need bech32m
+		{"bc", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd", ErrChecksum},
+		{"bc", "BC1S0XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ54WELL", ErrChecksum},
+		{"bc", "bc1zw508d6qejxtdg4y5r3zarvary0c5xw7kj7gz7z", ErrChecksum},
+		{"bc", "bc1p38j9r5y49hruaue7wxjce0updqjuyyx0kh56v8s25huc6995vvpql3jow4", ErrFormat},
+		{"bc", "BC130XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ7ZWS8R", ErrVersion},
+		{"bc", "bc1pw5dgrnzv", ErrFormat},
+	}
+
+	for _, tt := range tests {
+		err := Bech32{HRP: tt.hrp}.Validate(tt.address)
+		if !errors.Is(err, tt.err) || (tt.err != nil) != (err != nil) {
+			t.Errorf("%s: err = %v, want %v", tt.address, err, tt.err)
+		}
+	}
+}
+
+func TestEIP55(t *testing.T) {
+	// vectors of EIP-55
+	tests := []struct {
+		address  string
+		checksum bool // RequireChecksum
+		err      error
+	}{
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", true, nil},
+		{"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359", true, nil},
+		{"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB", true, nil},
+		{"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb", true, nil},
+		{"0x52908400098527886E0F7030069857D2E4169EE7", false, nil},
+		{"0xde709f2102306220921060314715629080e2fb77", false, nil},
+		{"0xde709f2102306220921060314715629080e2fb77", true, ErrChecksum},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", false, ErrChecksum},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA", false, ErrFormat},
+		{"5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", false, ErrFormat},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeg", false, ErrFormat},
+	}
+
+	for _, tt := range tests {
+		err := EIP55{RequireChecksum: tt.checksum}.Validate(tt.address)
+		if !errors.Is(err, tt.err) || (tt.err != nil) != (err != nil) {
+			t.Errorf("%s: err = %v, want %v", tt.address, err, tt.err)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	tests := []struct {
+		lenient bool
+		coin    string
+		address string
+		err     error
+	}{
+		{false, "BTC", "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", nil},
+		{false, "btc", "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", nil},
+		{false, "btc", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", nil},
+		{false, "btc", "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN3", ErrChecksum},
+		{false, "ltc", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", ErrNetwork},
+		{false, "eth", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", nil},
+		{false, "xrp", "rEb8TK3gBgk5auZkwc6sHnwrGVJH8DuaLh", ErrMemoRequired},
+		{false, "xrp", "rEb8TK3gBgk5auZkwc6sHnwrGVJH8DuaLh:12345", nil},
+		{false, "nosuchcoin", "anything", ErrUnknownCoin},
+		{true, "nosuchcoin", "anything", nil},
+	}
+
+	for _, tt := range tests {
+		r := NewRegistry()
+		r.Lenient = tt.lenient
+		err := r.ValidateAddress(tt.coin, tt.address)
+		if !errors.Is(err, tt.err) || (tt.err != nil) != (err != nil) {
+			t.Errorf("%s %s: err = %v, want %v", tt.coin, tt.address, err, tt.err)
+		}
+	}
+}
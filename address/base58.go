@@ -0,0 +1,64 @@
+package address
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// Base58Check validates legacy addresses (P2PKH and P2SH) by checksum and version byte
+type Base58Check struct {
+	Versions []byte // accepted version bytes (example: 0x00 and 0x05 for BTC)
+}
+
+// Validate checks the address
+func (v Base58Check) Validate(address string) error {
+	data, err := decodeBase58(address)
+	if err != nil {
+		return err
+	}
+	if len(data) != 25 {
+		return fail(ErrFormat, fmt.Sprintf("decoded length %d, want 25", len(data)))
+	}
+
+	payload, checksum := data[:21], data[21:]
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	if !bytes.Equal(second[:4], checksum) {
+		return fail(ErrChecksum, "")
+	}
+
+	if len(v.Versions) > 0 && bytes.IndexByte(v.Versions, payload[0]) < 0 {
+		return fail(ErrVersion, fmt.Sprintf("version 0x%02x", payload[0]))
+	}
+
+	return nil
+}
+
+// decodeBase58 decodes a base58 string keeping leading zero bytes
+func decodeBase58(s string) ([]byte, error) {
+	if s == "" {
+		return nil, fail(ErrFormat, "empty address")
+	}
+
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for i := 0; i < len(s); i++ {
+		d := bytes.IndexByte([]byte(base58Alphabet), s[i])
+		if d < 0 {
+			return nil, fail(ErrFormat, fmt.Sprintf("invalid base58 character %q", s[i]))
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(d)))
+	}
+
+	var zeros int
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
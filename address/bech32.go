@@ -0,0 +1,123 @@
+package address
+
+import (
+	"fmt"
+	"strings"
+)
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// checksum constants of BIP-173 (bech32) and BIP-350 (bech32m)
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+// Bech32 validates native segwit addresses: bech32 for witness version 0 and
+// bech32m for later versions
+type Bech32 struct {
+	HRP string // human-readable part (example: bc for BTC, ltc for LTC)
+}
+
+// Validate checks the address
+func (v Bech32) Validate(address string) error {
+	if strings.ToLower(address) != address && strings.ToUpper(address) != address {
+		return fail(ErrFormat, "mixed case")
+	}
+	address = strings.ToLower(address)
+	if len(address) > 90 {
+		return fail(ErrFormat, "too long")
+	}
+
+	sep := strings.LastIndexByte(address, '1')
+	if sep < 1 || sep+7 > len(address) {
+		return fail(ErrFormat, "no separator")
+	}
+	hrp, rest := address[:sep], address[sep+1:]
+	if hrp != v.HRP {
+		return fail(ErrNetwork, fmt.Sprintf("prefix %q, want %q", hrp, v.HRP))
+	}
+
+	data := make([]byte, len(rest))
+	for i := 0; i < len(rest); i++ {
+		d := strings.IndexByte(bech32Charset, rest[i])
+		if d < 0 {
+			return fail(ErrFormat, fmt.Sprintf("invalid bech32 character %q", rest[i]))
+		}
+		data[i] = byte(d)
+	}
+
+	polymod := bech32Polymod(append(bech32ExpandHRP(hrp), data...))
+	data = data[:len(data)-6]
+	if len(data) == 0 {
+		return fail(ErrFormat, "no witness version")
+	}
+
+	version := data[0]
+	if version > 16 {
+		return fail(ErrVersion, fmt.Sprintf("witness version %d", version))
+	}
+	want := uint32(bech32mConst)
+	if version == 0 {
+		want = bech32Const
+	}
+	if polymod != want {
+		return fail(ErrChecksum, "")
+	}
+
+	program, ok := convertBits(data[1:], 5, 8)
+	if !ok || len(program) < 2 || len(program) > 40 {
+		return fail(ErrFormat, "invalid witness program")
+	}
+	if version == 0 && len(program) != 20 && len(program) != 32 {
+		return fail(ErrFormat, fmt.Sprintf("witness program length %d", len(program)))
+	}
+
+	return nil
+}
+
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32ExpandHRP(hrp string) []byte {
+	expanded := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	return expanded
+}
+
+// convertBits regroups bits without padding, as required for decoding
+func convertBits(data []byte, from, to uint) ([]byte, bool) {
+	var acc, bits uint
+	maxv := uint(1)<<to - 1
+	var out []byte
+	for _, v := range data {
+		acc = acc<<from | uint(v)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if bits >= from || (acc<<(to-bits))&maxv != 0 {
+		return nil, false
+	}
+	return out, true
+}
@@ -0,0 +1,60 @@
+package address
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// EIP55 validates Ethereum and ERC-20 addresses with the mixed-case checksum of EIP-55
+type EIP55 struct {
+	RequireChecksum bool // reject all lower or all upper case addresses that carry no checksum
+}
+
+// Validate checks the address
+func (v EIP55) Validate(address string) error {
+	if !strings.HasPrefix(address, "0x") && !strings.HasPrefix(address, "0X") {
+		return fail(ErrFormat, "no 0x prefix")
+	}
+	hexPart := address[2:]
+	if len(hexPart) != 40 {
+		return fail(ErrFormat, "want 40 hex digits")
+	}
+	if _, err := hex.DecodeString(hexPart); err != nil {
+		return fail(ErrFormat, "not a hex string")
+	}
+
+	lower, upper := strings.ToLower(hexPart), strings.ToUpper(hexPart)
+	if hexPart == lower || hexPart == upper {
+		if v.RequireChecksum {
+			return fail(ErrChecksum, "address has no checksum")
+		}
+		return nil
+	}
+
+	if hexPart != checksumEIP55(lower) {
+		return fail(ErrChecksum, "")
+	}
+
+	return nil
+}
+
+// checksumEIP55 returns the mixed-case form of a lower case hex address
+func checksumEIP55(lower string) string {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(lower))
+	digest := hash.Sum(nil)
+
+	out := []byte(lower)
+	for i := range out {
+		nibble := digest[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if out[i] >= 'a' && nibble&0xf >= 8 {
+			out[i] -= 'a' - 'A'
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,43 @@
+package address
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Reasons of validation errors
+var (
+	ErrFormat       = errors.New("invalid format")
+	ErrChecksum     = errors.New("invalid checksum")
+	ErrVersion      = errors.New("unexpected address version")
+	ErrNetwork      = errors.New("address of another network")
+	ErrMemoRequired = errors.New("memo/tag is required")
+	ErrUnknownCoin  = errors.New("no validator for the coin")
+)
+
+// Error is a typed validation error
+type Error struct {
+	Coin    string // coin the address was checked for
+	Address string // checked address
+	Reason  error  // one of the Err reasons
+	Detail  string // additional description
+}
+
+// Error returns the error text
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("address: %s address %q: %v", e.Coin, e.Address, e.Reason)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	return msg
+}
+
+// Unwrap returns the reason, so errors.Is(err, ErrChecksum) works
+func (e *Error) Unwrap() error {
+	return e.Reason
+}
+
+// fail returns a validation error without coin and address, filled in by the Registry
+func fail(reason error, detail string) error {
+	return &Error{Reason: reason, Detail: detail}
+}
@@ -0,0 +1,211 @@
+// Package arbitrage scans Yobit pairs for triangular arbitrage opportunities.
+// It only reports opportunities and never places orders.
+package arbitrage
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	api "github.com/vladivolo/yobit-api"
+	"github.com/vladivolo/yobit-api/convert"
+)
+
+// depthBatch is the number of pairs requested in one Depth call
+const depthBatch = 50
+
+// Settings configures the Scanner
+type Settings struct {
+	Start     []string // coins triangles start and end with (example: btc, eth, doge, usd)
+	Threshold float64  // minimal profit after fees reported (example: 0.002 for 0.2%)
+	MinAmount float64  // minimal executable amount of the start coin reported
+}
+
+// Leg is a leg of a triangle priced from the top of the book
+type Leg struct {
+	convert.Edge
+	Size float64 // amount available at the top of the book in base currency
+}
+
+// Opportunity is a triangle that returns more than it takes
+type Opportunity struct {
+	Path      string    // coins of the triangle (example: btc>ltc>doge>btc)
+	Legs      [3]Leg    // legs of the triangle
+	Profit    float64   // profit after fees as a share of the amount (example: 0.004)
+	MaxAmount float64   // largest amount of the start coin executable at the top of the book
+	Time      time.Time // time of the scan
+}
+
+// Scanner evaluates triangles against fresh order books
+type Scanner struct {
+	market    api.MarketData
+	graph     *convert.Graph
+	settings  Settings
+	triangles []convert.Path
+	pairs     []string
+
+	// Errors is called for scan errors in Run, if set
+	Errors func(error)
+}
+
+// NewScanner is a constructor for the Scanner. Triangles are enumerated from the active pairs of info.
+func NewScanner(market api.MarketData, info api.Info, settings Settings) *Scanner {
+	s := &Scanner{
+		market:   market,
+		graph:    convert.NewGraph(info),
+		settings: settings,
+	}
+	s.enumerate()
+
+	return s
+}
+
+// Triangles returns the number of triangles watched
+func (s *Scanner) Triangles() int {
+	return len(s.triangles)
+}
+
+// Run scans every interval and sends opportunities to out until the context is done
+func (s *Scanner) Run(ctx context.Context, interval time.Duration, out chan<- Opportunity) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		opportunities, err := s.Scan()
+		if err != nil && s.Errors != nil {
+			s.Errors(err)
+		}
+		for _, o := range opportunities {
+			select {
+			case out <- o:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan loads the top of the book of all watched pairs once and returns opportunities
+// above the threshold ordered by profit. Prices of the previous scan are never used:
+// pairs missing in the fresh books are left unpriced.
+func (s *Scanner) Scan() ([]Opportunity, error) {
+	for _, pair := range s.pairs {
+		s.graph.SetPrices(pair, 0, 0)
+	}
+
+	sizes := make(map[string][2]float64, len(s.pairs)) // bid and ask sizes by pair
+	for pairs := s.pairs; len(pairs) > 0; {
+		n := len(pairs)
+		if n > depthBatch {
+			n = depthBatch
+		}
+		depth, err := s.market.Depth(&api.DepthSettings{
+			Pair:  strings.Join(pairs[:n], "-"),
+			Limit: 1,
+		})
+		if err != nil {
+			return nil, err
+		}
+		s.graph.UpdateDepth(depth)
+		for pair, data := range depth.PairData {
+			var size [2]float64
+			if len(data.Bids) > 0 {
+				size[0] = data.Bids[0][1]
+			}
+			if len(data.Asks) > 0 {
+				size[1] = data.Asks[0][1]
+			}
+			sizes[pair] = size
+		}
+		pairs = pairs[n:]
+	}
+
+	now := time.Now()
+	var opportunities []Opportunity
+	for _, triangle := range s.triangles {
+		o, ok := s.evaluate(s.graph.Quote(triangle), sizes)
+		if !ok || o.Profit < s.settings.Threshold || o.MaxAmount < s.settings.MinAmount {
+			continue
+		}
+		o.Time = now
+		opportunities = append(opportunities, o)
+	}
+	sort.Slice(opportunities, func(i, j int) bool {
+		return opportunities[i].Profit > opportunities[j].Profit
+	})
+
+	return opportunities, nil
+}
+
+// evaluate returns the profit and executable size of a priced triangle
+func (s *Scanner) evaluate(path convert.Path, sizes map[string][2]float64) (Opportunity, bool) {
+	o := Opportunity{
+		Path:      path.String(),
+		MaxAmount: math.Inf(1),
+	}
+
+	rate := 1.0 // amount of the leg's From coin per one start coin
+	for i, e := range path {
+		if e.Rate <= 0 {
+			return o, false
+		}
+
+		leg := Leg{Edge: e}
+		var capacity float64 // amount of From the top of the book takes
+		if e.Type == "sell" {
+			leg.Size = sizes[e.Pair][0]
+			capacity = leg.Size
+		} else {
+			leg.Size = sizes[e.Pair][1]
+			capacity = leg.Size * e.Price
+		}
+		o.MaxAmount = math.Min(o.MaxAmount, capacity/rate)
+		o.Legs[i] = leg
+
+		rate *= e.Rate
+	}
+	o.Profit = rate - 1
+
+	return o, true
+}
+
+// enumerate finds all triangles starting at the start coins
+func (s *Scanner) enumerate() {
+	seen := make(map[string]bool)
+	pairs := make(map[string]bool)
+	for _, start := range s.settings.Start {
+		start = strings.ToLower(start)
+		for _, e1 := range s.graph.Edges(start) {
+			for _, e2 := range s.graph.Edges(e1.To) {
+				if e2.To == start {
+					continue
+				}
+				for _, e3 := range s.graph.Edges(e2.To) {
+					if e3.To != start {
+						continue
+					}
+					key := e1.Pair + "|" + e2.Pair + "|" + e3.Pair + "|" + start
+					if seen[key] {
+						continue
+					}
+					seen[key] = true
+					s.triangles = append(s.triangles, convert.Path{e1, e2, e3})
+					pairs[e1.Pair], pairs[e2.Pair], pairs[e3.Pair] = true, true, true
+				}
+			}
+		}
+	}
+
+	for pair := range pairs {
+		s.pairs = append(s.pairs, pair)
+	}
+	sort.Strings(s.pairs)
+}
@@ -0,0 +1,61 @@
+package arbitrage
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+
+	api "github.com/vladivolo/yobit-api"
+	"github.com/vladivolo/yobit-api/mock"
+)
+
+func TestScanDoesNotReuseStalePrices(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	market := mock.NewMockMarketData(ctrl)
+
+	info := api.Info{Pairs: map[string]map[string]interface{}{
+		"ltc_btc":  {},
+		"doge_ltc": {},
+		"doge_btc": {},
+	}}
+	// btc>ltc>doge>btc returns 2 btc for 1 btc at these prices
+	books := map[string]api.PData{
+		"ltc_btc":  {Bids: [][2]float64{{0.009, 100}}, Asks: [][2]float64{{0.01, 100}}},
+		"doge_ltc": {Bids: [][2]float64{{0.009, 1e6}}, Asks: [][2]float64{{0.01, 1e6}}},
+		"doge_btc": {Bids: [][2]float64{{0.0002, 1e6}}, Asks: [][2]float64{{0.0003, 1e6}}},
+	}
+
+	tests := []struct {
+		name    string
+		missing string // pair missing in the depth response
+		found   bool
+	}{
+		{"all pairs priced", "", true},
+		{"pair missing in the next scan", "doge_btc", false},
+		{"pair back", "", true},
+	}
+
+	scanner := NewScanner(market, info, Settings{Start: []string{"btc"}, Threshold: 0.5})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			market.EXPECT().Depth(gomock.Any()).DoAndReturn(func(s *api.DepthSettings) (api.Depth, error) {
+				depth := api.Depth{Success: 1, PairData: make(map[string]api.PData)}
+				for _, pair := range strings.Split(s.Pair, "-") {
+					if pair != tt.missing {
+						depth.PairData[pair] = books[pair]
+					}
+				}
+				return depth, nil
+			})
+
+			opportunities, err := scanner.Scan()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found := len(opportunities) > 0; found != tt.found {
+				t.Fatalf("opportunities = %+v, want found %v", opportunities, tt.found)
+			}
+		})
+	}
+}
@@ -0,0 +1,369 @@
+// Package audit keeps an append-only, hash-chained log of private API actions:
+// orders, cancels, withdrawals and Yobicode operations. Log.Interceptor records
+// the intent of every call before it is sent and its outcome after it, and Verify
+// detects edited, removed or reordered entries.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// DefaultMethods are the Trade API methods audited by default
+var DefaultMethods = []string{"Trade", "CancelOrder", "WithdrawCoinsToAddress", "CreateYobicode", "RedeemYobicode"}
+
+// Phases of the entries of a call
+const (
+	PhaseIntent  = "intent"  // written before the call is sent
+	PhaseOutcome = "outcome" // written after the call with its result
+)
+
+// redacted replaces coupons in parameters and results
+const redacted = "[REDACTED]"
+
+// Entry is a record of one call
+type Entry struct {
+	Seq      uint64            `json:"seq"`       // number of the entry, starting from 1
+	Phase    string            `json:"phase"`     // intent or outcome
+	Intent   uint64            `json:"intent"`    // number of the intent entry of an outcome, 0 if it wasn't written
+	Sent     time.Time         `json:"sent"`      // time the call was sent
+	Finished time.Time         `json:"finished"`  // time the result was received, zero for intents
+	Method   string            `json:"method"`    // Trade API method
+	Params   map[string]string `json:"params"`    // request parameters, coupons redacted
+	Status   int               `json:"status"`    // HTTP status, 0 without a response
+	Result   json.RawMessage   `json:"result"`    // response, coupons redacted
+	Error    string            `json:"error"`     // request error
+	PrevHash string            `json:"prev_hash"` // hash of the previous entry, empty for the first one
+	Hash     string            `json:"hash"`      // hash of this entry with Hash empty
+}
+
+// hash returns the hash of the entry with Hash empty
+func (e Entry) hash() (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Log is an audit log file
+type Log struct {
+	// Errors receives errors of writing entries made by the interceptor. If it is nil,
+	// the interceptor fails closed: a call whose intent can't be written isn't sent,
+	// and a failure to write the outcome is returned as the error of the call.
+	Errors func(error)
+
+	methods map[string]bool
+
+	mu   sync.Mutex
+	file *os.File
+	seq  uint64
+	last string
+}
+
+// Open opens the log file for appending, creating it if needed. The file is verified
+// first, so a tampered log is never extended. Empty methods mean DefaultMethods.
+func Open(path string, methods ...string) (*Log, error) {
+	if len(methods) == 0 {
+		methods = DefaultMethods
+	}
+
+	l := &Log{
+		methods: make(map[string]bool, len(methods)),
+	}
+	for _, m := range methods {
+		l.methods[m] = true
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	result, err := Verify(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	l.file, l.seq, l.last = f, result.Entries, result.Hash
+
+	return l, nil
+}
+
+// Close closes the log file
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.file.Close()
+}
+
+// Head returns the number and hash of the last entry. Keeping them elsewhere lets
+// Verify detect entries removed from the end.
+func (l *Log) Head() (uint64, string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.seq, l.last
+}
+
+// Interceptor records the audited calls of the Trade API (plug in with api.WithInterceptors)
+func (l *Log) Interceptor() api.Interceptor {
+	return func(ctx context.Context, req *api.Request, next api.Handler) (*api.Response, error) {
+		if !req.Signed || !l.methods[req.Method] {
+			return next(ctx, req)
+		}
+
+		sent := time.Now().UTC()
+		intent, aerr := l.write(Entry{
+			Phase:  PhaseIntent,
+			Sent:   sent,
+			Method: req.Method,
+			Params: redactParams(req.Params),
+		})
+		if aerr != nil {
+			if l.Errors == nil {
+				return nil, fmt.Errorf("audit: %s not sent, intent not recorded: %v", req.Method, aerr)
+			}
+			l.Errors(aerr)
+		}
+
+		resp, err := next(ctx, req)
+
+		e := Entry{
+			Phase:    PhaseOutcome,
+			Intent:   intent,
+			Sent:     sent,
+			Finished: time.Now().UTC(),
+			Method:   req.Method,
+			Params:   redactParams(req.Params),
+		}
+		if resp != nil {
+			e.Status = resp.Status
+			e.Result = redactResult(resp.Body)
+		}
+		if err != nil {
+			e.Error = err.Error()
+		}
+
+		if _, aerr := l.write(e); aerr != nil {
+			if l.Errors == nil && err == nil {
+				return resp, fmt.Errorf("audit: %s sent, outcome not recorded: %v", req.Method, aerr)
+			}
+			if l.Errors != nil {
+				l.Errors(aerr)
+			}
+		}
+
+		return resp, err
+	}
+}
+
+// Append chains the entry to the log and writes it. Seq, PrevHash and Hash are set here.
+func (l *Log) Append(e Entry) error {
+	_, err := l.write(e)
+	return err
+}
+
+// write writes the entry and returns its number
+func (l *Log) write(e Entry) (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e.Seq = l.seq + 1
+	e.PrevHash = l.last
+	hash, err := e.hash()
+	if err != nil {
+		return 0, err
+	}
+	e.Hash = hash
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return 0, err
+	}
+	_, err = l.file.Write(append(data, '\n'))
+	if err != nil {
+		return 0, err
+	}
+	err = l.file.Sync()
+	if err != nil {
+		return 0, err
+	}
+
+	l.seq, l.last = e.Seq, e.Hash
+	return e.Seq, nil
+}
+
+// Result is the summary of a verified log
+type Result struct {
+	Entries uint64 // number of entries
+	Hash    string // hash of the last entry
+}
+
+// VerifyError describes the first broken entry of a log
+type VerifyError struct {
+	Line   int    // line of the entry
+	Seq    uint64 // number of the entry, if it could be read
+	Reason string
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("audit: line %d (entry %d): %s", e.Line, e.Seq, e.Reason)
+}
+
+// ErrTruncated is returned by VerifyHead when the log ends before the known head
+var ErrTruncated = errors.New("audit: log is shorter than the recorded head")
+
+// Verify reads the log and checks numbering, chaining and hashes of all entries
+func Verify(r io.Reader) (Result, error) {
+	var result Result
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		var e Entry
+		err := json.Unmarshal(scanner.Bytes(), &e)
+		if err != nil {
+			return result, &VerifyError{Line: line, Reason: "malformed entry: " + err.Error()}
+		}
+		if e.Seq != result.Entries+1 {
+			return result, &VerifyError{Line: line, Seq: e.Seq, Reason: fmt.Sprintf("want entry %d, entries are missing or reordered", result.Entries+1)}
+		}
+		if e.PrevHash != result.Hash {
+			return result, &VerifyError{Line: line, Seq: e.Seq, Reason: "previous hash doesn't match"}
+		}
+		hash, err := e.hash()
+		if err != nil {
+			return result, err
+		}
+		if hash != e.Hash {
+			return result, &VerifyError{Line: line, Seq: e.Seq, Reason: "entry was modified"}
+		}
+
+		result.Entries, result.Hash = e.Seq, e.Hash
+	}
+
+	return result, scanner.Err()
+}
+
+// VerifyFile verifies the log file
+func VerifyFile(path string) (Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Result{}, err
+	}
+	defer f.Close()
+
+	return Verify(f)
+}
+
+// VerifyHead verifies the log file and checks that it still contains the entry
+// returned by Head earlier
+func VerifyHead(path string, seq uint64, hash string) (Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Result{}, err
+	}
+	defer f.Close()
+
+	result, err := Verify(f)
+	if err != nil {
+		return result, err
+	}
+	if result.Entries < seq {
+		return result, ErrTruncated
+	}
+
+	// read again up to the head to compare its hash
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return result, err
+	}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return result, err
+		}
+		if e.Seq == seq {
+			if e.Hash != hash {
+				return result, &VerifyError{Line: line, Seq: seq, Reason: "entry differs from the recorded head"}
+			}
+			break
+		}
+	}
+
+	return result, scanner.Err()
+}
+
+// redactParams copies the parameters without coupons
+func redactParams(values map[string][]string) map[string]string {
+	params := make(map[string]string, len(values))
+	for k, v := range values {
+		if len(v) > 0 {
+			params[k] = v[0]
+		}
+	}
+	if _, ok := params["coupon"]; ok {
+		params["coupon"] = redacted
+	}
+	return params
+}
+
+// redactResult returns the response with coupons redacted, non-JSON bodies as a string
+func redactResult(body []byte) json.RawMessage {
+	// numbers are kept as sent
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var v interface{}
+	if dec.Decode(&v) != nil {
+		data, _ := json.Marshal(string(body))
+		return data
+	}
+
+	data, err := json.Marshal(redactCoupons(v))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func redactCoupons(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, item := range t {
+			if k == "coupon" {
+				t[k] = redacted
+				continue
+			}
+			t[k] = redactCoupons(item)
+		}
+	case []interface{}:
+		for i, item := range t {
+			t[i] = redactCoupons(item)
+		}
+	}
+	return v
+}
@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+func TestInterceptor(t *testing.T) {
+	tests := []struct {
+		name     string
+		closeAt  string // "intent" - the log fails before the call, "outcome" - during it
+		errors   bool   // Errors is set
+		sent     bool
+		err      bool
+		reported int
+		phases   []string
+	}{
+		{"recorded", "", false, true, false, 0, []string{PhaseIntent, PhaseOutcome}},
+		{"intent fails closed", "intent", false, false, true, 0, nil},
+		{"intent reported", "intent", true, true, false, 2, nil},
+		{"outcome fails closed", "outcome", false, true, true, 0, []string{PhaseIntent}},
+		{"outcome reported", "outcome", true, true, false, 1, []string{PhaseIntent}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "audit.log")
+			l, err := Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reported := 0
+			if tt.errors {
+				l.Errors = func(error) { reported++ }
+			}
+			if tt.closeAt == "intent" {
+				l.Close()
+			}
+
+			sent := false
+			next := func(ctx context.Context, req *api.Request) (*api.Response, error) {
+				sent = true
+				if tt.closeAt == "outcome" {
+					l.Close()
+				}
+				return &api.Response{Status: 200, Body: []byte(`{"success":1,"return":{"order_id":7}}`)}, nil
+			}
+			req := &api.Request{
+				RequestInfo: api.RequestInfo{API: "trade", Method: "Trade"},
+				Params:      url.Values{"method": {"Trade"}, "pair": {"ltc_btc"}},
+				Signed:      true,
+			}
+
+			_, err = l.Interceptor()(context.Background(), req, next)
+			if sent != tt.sent || (err != nil) != tt.err || reported != tt.reported {
+				t.Fatalf("sent %v, err %v, reported %d; want %v, error %v, %d", sent, err, reported, tt.sent, tt.err, tt.reported)
+			}
+			l.Close()
+
+			entries := readEntries(t, path)
+			if len(entries) != len(tt.phases) {
+				t.Fatalf("entries = %+v, want phases %v", entries, tt.phases)
+			}
+			for i, e := range entries {
+				if e.Phase != tt.phases[i] {
+					t.Fatalf("entry %d phase = %s, want %s", i+1, e.Phase, tt.phases[i])
+				}
+			}
+			if len(entries) == 2 && entries[1].Intent != entries[0].Seq {
+				t.Fatalf("outcome intent = %d, want %d", entries[1].Intent, entries[0].Seq)
+			}
+		})
+	}
+}
+
+func TestVerifyTampered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, method := range []string{"Trade", "CancelOrder"} {
+		if err := l.Append(Entry{Phase: PhaseIntent, Method: method}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	seq, hash := l.Head()
+	l.Close()
+
+	if _, err := VerifyHead(path, seq, hash); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyHead(path, seq+1, hash); !errors.Is(err, ErrTruncated) {
+		t.Fatalf("VerifyHead past the end = %v, want ErrTruncated", err)
+	}
+}
+
+// readEntries verifies the log and returns its entries
+func readEntries(t *testing.T, path string) []Entry {
+	t.Helper()
+
+	if _, err := VerifyFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []Entry
+	for _, line := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
@@ -2,24 +2,156 @@ package api
 
 import ()
 
-// Client is a main struct for executing all the operations of the API
+// Client is a main struct for executing all the operations of the API. It implements
+// MarketData and Trading by forwarding to Market() and Trading().
 type Client struct {
 	apiKey    string
 	apiSecret string
 
-	Public *PublicAPI
-	Trade  *TradeAPI
+	Public  *PublicAPI
+	Private *TradeAPI // the Trade API
+
+	market  MarketData
+	trading Trading
+}
+
+// ClientOption configures the Client
+type ClientOption func(*Client)
+
+// WithMarketData replaces the market data implementation returned by Client.Market
+func WithMarketData(m MarketData) ClientOption {
+	return func(c *Client) {
+		c.market = m
+	}
+}
+
+// WithTrading replaces the trading implementation returned by Client.Trading (e.g. paper trading)
+func WithTrading(t Trading) ClientOption {
+	return func(c *Client) {
+		c.trading = t
+	}
+}
+
+// WithAddressValidator sets the validator of withdrawal addresses of the Trade API
+func WithAddressValidator(v AddressValidator) ClientOption {
+	return func(c *Client) {
+		c.Private.AddressValidator = v
+	}
+}
+
+// WithDriftHandler reports response fields unknown to the models of both APIs
+func WithDriftHandler(h DriftHandler) ClientOption {
+	return func(c *Client) {
+		c.Public.Drift = h
+		c.Private.Drift = h
+	}
 }
 
 // NewClient is a constructor for the Client
-func NewClient(api_key string, api_secret string) *Client {
+func NewClient(api_key string, api_secret string, opts ...ClientOption) *Client {
 	client := &Client{
 		apiKey:    api_key,
 		apiSecret: api_secret,
 	}
 
 	client.Public = NewPublicAPI(api_key, api_secret)
-	client.Trade = NewTradeAPI(api_key, api_secret)
+	client.Private = NewTradeAPI(api_key, api_secret)
+	client.market = client.Public
+	client.trading = client.Private
+
+	for _, opt := range opts {
+		opt(client)
+	}
 
 	return client
 }
+
+// Market returns market data operations: the Public API unless replaced with WithMarketData
+func (c *Client) Market() MarketData {
+	return c.market
+}
+
+// Trading returns trade operations: the Trade API unless replaced with WithTrading
+func (c *Client) Trading() Trading {
+	return c.trading
+}
+
+// Info returns information about server time and active pairs.
+func (c *Client) Info() (Info, error) {
+	return c.market.Info()
+}
+
+// Ticker provides statistic data for the last 24 hours.
+func (c *Client) Ticker(t *TickerSettings) (Ticker, error) {
+	return c.market.Ticker(t)
+}
+
+// Depth returns information about lists of active orders for selected pairs.
+func (c *Client) Depth(t *DepthSettings) (Depth, error) {
+	return c.market.Depth(t)
+}
+
+// Trades returns information about the last transactions of selected pairs.
+func (c *Client) Trades(t *TradesSettings) (Trades, error) {
+	return c.market.Trades(t)
+}
+
+// OpenInterest returns the first ask and bid of the symbol.
+func (c *Client) OpenInterest(symbol string) (float64, float64, error) {
+	return c.market.OpenInterest(symbol)
+}
+
+// OpenInterests returns the first asks and bids of the symbols.
+func (c *Client) OpenInterests(symbols []string) (map[string]TData, error) {
+	return c.market.OpenInterests(symbols)
+}
+
+// GetInfo shows info about account's balance.
+func (c *Client) GetInfo() (GetInfo, error) {
+	return c.trading.GetInfo()
+}
+
+// Trade allows creating new orders.
+func (c *Client) Trade(t *TradeSettings) (Trade, error) {
+	return c.trading.Trade(t)
+}
+
+// ActiveOrders returns list of user's active orders.
+func (c *Client) ActiveOrders(t *ActiveOrdersSettings) (ActiveOrders, error) {
+	return c.trading.ActiveOrders(t)
+}
+
+// OrderInfo returns detailed information about the chosen order.
+func (c *Client) OrderInfo(t *OrderInfoSettings) (OrderInfo, error) {
+	return c.trading.OrderInfo(t)
+}
+
+// CancelOrder cancels the chosen order.
+func (c *Client) CancelOrder(t *CancelOrderSettings) (CancelOrder, error) {
+	return c.trading.CancelOrder(t)
+}
+
+// TradeHistory returns transaction history.
+func (c *Client) TradeHistory(t *TradeHistorySettings) (TradeHistory, error) {
+	return c.trading.TradeHistory(t)
+}
+
+// GetDepositAddress returns deposit address.
+func (c *Client) GetDepositAddress(t *GetDepositAddressSettings) (GetDepositAddress, error) {
+	return c.trading.GetDepositAddress(t)
+}
+
+// WithdrawCoinsToAddress creates withdrawal request.
+func (c *Client) WithdrawCoinsToAddress(t *WithdrawCoinsToAddressSettings) (WithdrawCoinsToAddress, error) {
+	return c.trading.WithdrawCoinsToAddress(t)
+}
+
+// CreateYobicode allows you to create Yobicodes (coupons).
+func (c *Client) CreateYobicode(t *CreateYobicodeSettings) (CreateYobicode, error) {
+	return c.trading.CreateYobicode(t)
+}
+
+// RedeemYobicode is used to redeem Yobicodes (coupons).
+func (c *Client) RedeemYobicode(t *RedeemYobicodeSettings) (RedeemYobicode, error) {
+	return c.trading.RedeemYobicode(t)
+}
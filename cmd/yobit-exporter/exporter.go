@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	api "github.com/vladivolo/yobit-api"
+	"github.com/vladivolo/yobit-api/metrics"
+)
+
+const namespace = "yobit"
+
+// exporter polls the API and keeps the gauges
+type exporter struct {
+	market   api.MarketData
+	pairs    []string
+	accounts map[string]api.Trading // by account name
+
+	last        *prometheus.GaugeVec
+	bid         *prometheus.GaugeVec
+	ask         *prometheus.GaugeVec
+	volume      *prometheus.GaugeVec
+	quoteVolume *prometheus.GaugeVec
+	spread      *prometheus.GaugeVec
+	spreadRatio *prometheus.GaugeVec
+	balance     *prometheus.GaugeVec
+	up          *prometheus.GaugeVec
+	lastSuccess *prometheus.GaugeVec
+	pollErrors  *prometheus.CounterVec
+}
+
+func newExporter(reg prometheus.Registerer, pairs []string, accounts []account) (*exporter, error) {
+	gauge := func(name, help string, labels ...string) *prometheus.GaugeVec {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: namespace, Name: name, Help: help}, labels)
+	}
+
+	e := &exporter{
+		market:      api.NewPublicAPI("", ""),
+		pairs:       pairs,
+		accounts:    make(map[string]api.Trading, len(accounts)),
+		last:        gauge("last_price", "Last trade price of the pair.", "pair"),
+		bid:         gauge("bid_price", "Best bid of the pair.", "pair"),
+		ask:         gauge("ask_price", "Best ask of the pair.", "pair"),
+		volume:      gauge("volume_24h", "24h traded volume of the pair in the base currency.", "pair"),
+		quoteVolume: gauge("quote_volume_24h", "24h traded volume of the pair in the quote currency.", "pair"),
+		spread:      gauge("spread", "Best ask minus best bid of the pair.", "pair"),
+		spreadRatio: gauge("spread_ratio", "Spread of the pair relative to the mid price.", "pair"),
+		balance:     gauge("balance", "Balance of the account by coin, free or in orders.", "account", "coin", "state"),
+		up:          gauge("exporter_up", "Whether the last poll of the target succeeded.", "target"),
+		lastSuccess: gauge("exporter_last_success_timestamp_seconds", "Unix time of the last successful poll of the target.", "target"),
+		pollErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "exporter_errors_total",
+			Help:      "Failed polls by target and error class.",
+		}, []string{"target", "class"}),
+	}
+	for _, a := range accounts {
+		e.accounts[a.Name] = api.NewTradeAPI(a.Key, a.Secret)
+	}
+
+	for _, c := range []prometheus.Collector{e.last, e.bid, e.ask, e.volume, e.quoteVolume, e.spread,
+		e.spreadRatio, e.balance, e.up, e.lastSuccess, e.pollErrors} {
+		err := reg.Register(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return e, nil
+}
+
+// checkPairs returns an error if Info doesn't list all the pairs
+func (e *exporter) checkPairs() error {
+	info, err := e.market.Info()
+	if err != nil {
+		return err
+	}
+	if info.Error != "" {
+		return &api.APIError{Message: info.Error}
+	}
+
+	for _, pair := range e.pairs {
+		if _, ok := info.Pairs[pair]; !ok {
+			return fmt.Errorf("unknown pair %q", pair)
+		}
+	}
+	return nil
+}
+
+// run polls every interval until the context is done
+func (e *exporter) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if len(e.pairs) > 0 {
+			e.report("ticker", e.pollTicker())
+		}
+		for name, trading := range e.accounts {
+			e.report("account:"+name, e.pollAccount(name, trading))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// report updates the health gauges of the target
+func (e *exporter) report(target string, err error) {
+	if err != nil {
+		log.Printf("yobit-exporter: %s: %v", target, err)
+		e.up.WithLabelValues(target).Set(0)
+		e.pollErrors.WithLabelValues(target, metrics.Class(err)).Inc()
+		return
+	}
+	e.up.WithLabelValues(target).Set(1)
+	e.lastSuccess.WithLabelValues(target).SetToCurrentTime()
+}
+
+func (e *exporter) pollTicker() error {
+	tickers, err := api.Tickers(e.market, e.pairs)
+	if err != nil {
+		return err
+	}
+
+	for _, pair := range e.pairs {
+		t, ok := tickers[pair]
+		if !ok {
+			// delisted or misspelled, don't export stale values
+			for _, g := range []*prometheus.GaugeVec{e.last, e.bid, e.ask, e.volume, e.quoteVolume, e.spread, e.spreadRatio} {
+				g.DeleteLabelValues(pair)
+			}
+			continue
+		}
+
+		e.last.WithLabelValues(pair).Set(t.Last)
+		e.bid.WithLabelValues(pair).Set(t.Buy)
+		e.ask.WithLabelValues(pair).Set(t.Sell)
+		e.volume.WithLabelValues(pair).Set(t.VolCur)
+		e.quoteVolume.WithLabelValues(pair).Set(t.Vol)
+		if t.Buy > 0 && t.Sell > 0 {
+			e.spread.WithLabelValues(pair).Set(t.Sell - t.Buy)
+			e.spreadRatio.WithLabelValues(pair).Set((t.Sell - t.Buy) / ((t.Sell + t.Buy) / 2))
+		} else {
+			// one side of the book is empty
+			e.spread.DeleteLabelValues(pair)
+			e.spreadRatio.DeleteLabelValues(pair)
+		}
+	}
+
+	return nil
+}
+
+func (e *exporter) pollAccount(name string, trading api.Trading) error {
+	info, err := trading.GetInfo()
+	if err != nil {
+		return err
+	}
+	if info.Success == 0 {
+		if info.Error == "" {
+			info.Error = "request failed"
+		}
+		return &api.APIError{Message: info.Error}
+	}
+
+	// coins that are gone from the account aren't exported with stale balances
+	e.balance.DeletePartialMatch(prometheus.Labels{"account": name})
+	for coin, total := range info.Return.FundsInclOrders {
+		free := info.Return.Funds[coin]
+		e.balance.WithLabelValues(name, coin, "free").Set(free)
+		e.balance.WithLabelValues(name, coin, "in_orders").Set(total - free)
+	}
+	for coin, free := range info.Return.Funds {
+		if _, ok := info.Return.FundsInclOrders[coin]; !ok {
+			e.balance.WithLabelValues(name, coin, "free").Set(free)
+		}
+	}
+
+	return nil
+}
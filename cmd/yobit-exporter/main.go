@@ -0,0 +1,136 @@
+// Command yobit-exporter is a Prometheus exporter of Yobit market and account gauges.
+//
+// Usage:
+//
+//	yobit-exporter -config file [-listen addr]
+//
+// It checks the configured pairs against Info, polls Ticker for them and GetInfo
+// for every configured key, and serves last price, bid/ask, 24h volume, spread and
+// per-coin balances (free and in orders) on /metrics. The config is a JSON file:
+//
+//	{
+//		"listen": ":9469",
+//		"interval": "30s",
+//		"pairs": ["btc_usdt", "eth_btc"],
+//		"accounts": [{"name": "main", "key": "...", "secret": "..."}]
+//	}
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// config is the config file
+type config struct {
+	Listen   string    `json:"listen"`   // address of the metrics server (on default: :9469)
+	Interval string    `json:"interval"` // time between polls (on default: 30s)
+	Pairs    []string  `json:"pairs"`    // pairs of the market gauges (example: btc_usdt)
+	Accounts []account `json:"accounts"` // accounts of the balance gauges
+}
+
+// account is an API key whose balances are exported
+type account struct {
+	Name   string `json:"name"`   // value of the account label
+	Key    string `json:"key"`    // API key
+	Secret string `json:"secret"` // API secret
+}
+
+func loadConfig(path string) (config, error) {
+	var cfg config
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	err = json.Unmarshal(data, &cfg)
+	if err != nil {
+		return cfg, fmt.Errorf("%s: %v", path, err)
+	}
+
+	if cfg.Listen == "" {
+		cfg.Listen = ":9469"
+	}
+	if cfg.Interval == "" {
+		cfg.Interval = "30s"
+	}
+	if len(cfg.Pairs) == 0 && len(cfg.Accounts) == 0 {
+		return cfg, errors.New("nothing to export: set pairs or accounts")
+	}
+	names := make(map[string]bool)
+	for _, a := range cfg.Accounts {
+		if a.Name == "" || a.Key == "" || a.Secret == "" {
+			return cfg, errors.New("accounts need a name, a key and a secret")
+		}
+		if names[a.Name] {
+			return cfg, fmt.Errorf("duplicate account %q", a.Name)
+		}
+		names[a.Name] = true
+	}
+
+	return cfg, nil
+}
+
+func main() {
+	configPath := flag.String("config", "", "config file")
+	listen := flag.String("listen", "", "address of the metrics server, overrides the config")
+	flag.Parse()
+
+	if *configPath == "" {
+		fmt.Fprintln(os.Stderr, "usage: yobit-exporter -config file [-listen addr]")
+		os.Exit(2)
+	}
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatal("yobit-exporter: ", err)
+	}
+	if *listen != "" {
+		cfg.Listen = *listen
+	}
+	interval, err := time.ParseDuration(cfg.Interval)
+	if err != nil || interval <= 0 {
+		log.Fatalf("yobit-exporter: bad interval %q", cfg.Interval)
+	}
+
+	reg := prometheus.NewRegistry()
+	e, err := newExporter(reg, cfg.Pairs, cfg.Accounts)
+	if err != nil {
+		log.Fatal("yobit-exporter: ", err)
+	}
+	if len(cfg.Pairs) > 0 {
+		err = e.checkPairs()
+		if err != nil {
+			log.Fatal("yobit-exporter: ", err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go e.run(ctx, interval)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	server := &http.Server{Addr: cfg.Listen, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	log.Printf("yobit-exporter: serving /metrics on %s", cfg.Listen)
+	err = server.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		log.Fatal("yobit-exporter: ", err)
+	}
+}
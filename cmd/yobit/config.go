@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	api "github.com/vladivolo/yobit-api"
+	"github.com/vladivolo/yobit-api/address"
+)
+
+// credentials are the API key and secret
+type credentials struct {
+	Key    string `json:"key"`
+	Secret string `json:"secret"`
+}
+
+// defaultConfigPath returns yobit/config.json in the user config directory
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "yobit", "config.json")
+}
+
+// loadCredentials reads the config file, if it exists, and overrides it with the environment
+func loadCredentials(path string) (credentials, error) {
+	var creds credentials
+
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return creds, err
+		}
+		if err == nil {
+			err = json.Unmarshal(data, &creds)
+			if err != nil {
+				return creds, err
+			}
+		}
+	}
+
+	if key := os.Getenv("YOBIT_API_KEY"); key != "" {
+		creds.Key = key
+	}
+	if secret := os.Getenv("YOBIT_API_SECRET"); secret != "" {
+		creds.Secret = secret
+	}
+
+	return creds, nil
+}
+
+// env holds the client of a command
+type env struct {
+	client  *api.Client
+	private bool // credentials are set
+}
+
+func newEnv(creds credentials) *env {
+	return &env{
+		client:  api.NewClient(creds.Key, creds.Secret, api.WithAddressValidator(address.NewRegistry())),
+		private: creds.Key != "" && creds.Secret != "",
+	}
+}
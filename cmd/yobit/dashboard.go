@@ -0,0 +1,439 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// limiter lets one request through every interval
+type limiter struct {
+	mu   sync.Mutex
+	next time.Time
+	gap  time.Duration
+}
+
+// Wait waits for the next free slot
+func (l *limiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	at := l.next
+	l.next = l.next.Add(l.gap)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// order is an open order of the selected pair
+type order struct {
+	id     uint64
+	typ    string
+	amount float64
+	rate   float64
+}
+
+// dashboard is the state of the terminal dashboard
+type dashboard struct {
+	client  *api.Client
+	private bool
+	pairs   []string
+
+	mu       sync.Mutex
+	selected int // selected pair
+	order    int // selected open order
+	tickers  map[string]api.TData
+	book     api.PData
+	tape     []api.TradeData
+	funds    map[string]float64
+	orders   []order
+	updated  time.Time
+	status   string
+	prompt   *prompt
+	refresh  chan struct{}
+	actions  chan func() string // Trade API actions run by the poll goroutine, returning the status
+	screen   tcell.Screen
+	quitting bool
+}
+
+// prompt is an input line or a confirmation shown in the status line
+type prompt struct {
+	label   string
+	input   string
+	confirm bool               // y/n question instead of an input line
+	done    func(input string) // called with the input, or "y" when confirmed
+}
+
+func runDashboard(env *env, args []string) (*output, error) {
+	flags := flag.NewFlagSet("dashboard", flag.ContinueOnError)
+	interval := flags.Duration("interval", 5*time.Second, "refresh interval")
+	rate := flags.Float64("rate", 2, "maximum API requests per second")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() == 0 {
+		return nil, errors.New("usage: yobit dashboard [-interval d] [-rate n] pair...")
+	}
+	if *rate <= 0 {
+		return nil, errors.New("rate must be positive")
+	}
+
+	l := &limiter{gap: time.Duration(float64(time.Second) / *rate)}
+	env.client.Public.Limiter = l
+	env.client.Private.Limiter = l
+
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return nil, err
+	}
+	err = screen.Init()
+	if err != nil {
+		return nil, err
+	}
+	defer screen.Fini()
+
+	d := &dashboard{
+		client:  env.client,
+		private: env.private,
+		pairs:   flags.Args(),
+		tickers: make(map[string]api.TData),
+		refresh: make(chan struct{}, 1),
+		actions: make(chan func() string, 1),
+		screen:  screen,
+		status:  "loading...",
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.poll(ctx, *interval)
+
+	d.loop()
+
+	// nothing to print after the screen is closed
+	return nil, nil
+}
+
+// poll refreshes the data every interval or when asked to and runs the queued
+// actions, so requests of the Trade API never run concurrently
+func (d *dashboard) poll(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		d.load()
+		d.screen.PostEvent(tcell.NewEventInterrupt(nil))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.refresh:
+		case action := <-d.actions:
+			d.setStatus(action())
+		}
+	}
+}
+
+// load requests the watchlist, the selected pair and the account
+func (d *dashboard) load() {
+	d.mu.Lock()
+	pair := d.pairs[d.selected]
+	d.mu.Unlock()
+
+	var errs []string
+	ticker, err := d.client.Public.Ticker(&api.TickerSettings{Pairs: d.pairs})
+	if err != nil {
+		errs = append(errs, "ticker: "+err.Error())
+	}
+	depth, err := d.client.Public.Depth(&api.DepthSettings{Pair: pair, Limit: 50})
+	if err != nil {
+		errs = append(errs, "depth: "+err.Error())
+	}
+	trades, err := d.client.Public.Trades(&api.TradesSettings{Pair: pair, Limit: 50})
+	if err != nil {
+		errs = append(errs, "trades: "+err.Error())
+	}
+
+	var info api.GetInfo
+	var active api.ActiveOrders
+	var loaded bool // active orders, kept on errors
+	if d.private {
+		info, err = d.client.Private.GetInfo()
+		if err == nil {
+			err = failed(info.Success, info.Error)
+		}
+		if err != nil {
+			errs = append(errs, "balance: "+err.Error())
+		}
+		active, err = d.client.Private.ActiveOrders(&api.ActiveOrdersSettings{Pair: pair})
+		if err == nil {
+			err = ordersFailed(active)
+		}
+		loaded = err == nil
+		if err != nil {
+			errs = append(errs, "orders: "+err.Error())
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for p, t := range ticker.PairData {
+		d.tickers[p] = t
+	}
+	if pair == d.pairs[d.selected] {
+		d.book = depth.PairData[pair]
+		d.tape = trades.PairData[pair]
+		if d.private && loaded {
+			d.orders = d.orders[:0]
+			for id, o := range active.Return {
+				d.orders = append(d.orders, order{id: id, typ: o.Type, amount: o.Amount, rate: o.Rate})
+			}
+			sort.Slice(d.orders, func(i, j int) bool { return d.orders[i].id < d.orders[j].id })
+			if d.order >= len(d.orders) {
+				d.order = 0
+			}
+		}
+	}
+	if d.private && info.Success != 0 {
+		d.funds = info.Return.FundsInclOrders
+	}
+	d.updated = time.Now()
+	if len(errs) > 0 {
+		d.status = strings.Join(errs, "; ")
+	} else if strings.HasPrefix(d.status, "loading") {
+		d.status = ""
+	}
+}
+
+// ordersFailed returns the error of an ActiveOrders response, the API reports an
+// empty list as the error "no orders"
+func ordersFailed(orders api.ActiveOrders) error {
+	if orders.Success == 0 && strings.EqualFold(strings.TrimSpace(orders.Error), "no orders") {
+		return nil
+	}
+	return failed(orders.Success, orders.Error)
+}
+
+// loop handles keys until the user quits
+func (d *dashboard) loop() {
+	for {
+		d.draw()
+
+		ev := d.screen.PollEvent()
+		switch ev := ev.(type) {
+		case *tcell.EventResize:
+			d.screen.Sync()
+		case *tcell.EventKey:
+			d.key(ev)
+			d.mu.Lock()
+			quit := d.quitting
+			d.mu.Unlock()
+			if quit {
+				return
+			}
+		}
+	}
+}
+
+// key handles a key press
+func (d *dashboard) key(ev *tcell.EventKey) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if ev.Key() == tcell.KeyCtrlC {
+		d.quitting = true
+		return
+	}
+
+	if p := d.prompt; p != nil {
+		switch {
+		case ev.Key() == tcell.KeyEscape:
+			d.prompt = nil
+		case p.confirm:
+			d.prompt = nil
+			if ev.Rune() == 'y' || ev.Rune() == 'Y' {
+				p.done("y")
+			} else {
+				d.status = "cancelled"
+			}
+		case ev.Key() == tcell.KeyEnter:
+			d.prompt = nil
+			p.done(p.input)
+		case ev.Key() == tcell.KeyBackspace || ev.Key() == tcell.KeyBackspace2:
+			if len(p.input) > 0 {
+				p.input = p.input[:len(p.input)-1]
+			}
+		case ev.Key() == tcell.KeyRune:
+			p.input += string(ev.Rune())
+		}
+		return
+	}
+
+	switch ev.Key() {
+	case tcell.KeyUp:
+		d.selectPair(d.selected - 1)
+		return
+	case tcell.KeyDown:
+		d.selectPair(d.selected + 1)
+		return
+	case tcell.KeyTab:
+		if len(d.orders) > 0 {
+			d.order = (d.order + 1) % len(d.orders)
+		}
+		return
+	}
+
+	switch ev.Rune() {
+	case 'q':
+		d.quitting = true
+	case 'r':
+		d.wake()
+	case 'b':
+		d.askOrder("buy")
+	case 's':
+		d.askOrder("sell")
+	case 'c':
+		d.askCancel()
+	}
+}
+
+// selectPair selects the pair and refreshes its data
+func (d *dashboard) selectPair(i int) {
+	if i < 0 || i >= len(d.pairs) || i == d.selected {
+		return
+	}
+	d.selected, d.order = i, 0
+	d.book, d.tape, d.orders = api.PData{}, nil, nil
+	d.wake()
+}
+
+// queue hands the action to the poll goroutine, the data is reloaded after it
+func (d *dashboard) queue(action func() string) {
+	select {
+	case d.actions <- action:
+		d.status = "sending..."
+	default:
+		d.status = "busy, try again"
+	}
+}
+
+func (d *dashboard) wake() {
+	select {
+	case d.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// askOrder asks for the amount and rate of an order and confirms it
+func (d *dashboard) askOrder(typ string) {
+	if !d.private {
+		d.status = "trading needs API credentials"
+		return
+	}
+	pair := d.pairs[d.selected]
+
+	d.prompt = &prompt{
+		label: fmt.Sprintf("%s %s amount@rate: ", typ, pair),
+		done: func(input string) {
+			settings, err := parseOrder(pair, typ, input)
+			if err != nil {
+				d.status = err.Error()
+				return
+			}
+			d.prompt = &prompt{
+				label:   fmt.Sprintf("%s %s %s @ %s? (y/n) ", typ, formatFloat(settings.Amount), pair, formatFloat(settings.Rate)),
+				confirm: true,
+				done: func(string) {
+					d.queue(func() string { return d.placeOrder(settings) })
+				},
+			}
+		},
+	}
+}
+
+// askCancel confirms cancelling the selected order
+func (d *dashboard) askCancel() {
+	if len(d.orders) == 0 {
+		d.status = "no open orders"
+		return
+	}
+	o := d.orders[d.order]
+
+	d.prompt = &prompt{
+		label:   fmt.Sprintf("cancel order %d (%s %s @ %s)? (y/n) ", o.id, o.typ, formatFloat(o.amount), formatFloat(o.rate)),
+		confirm: true,
+		done: func(string) {
+			d.queue(func() string { return d.cancelOrder(o.id) })
+		},
+	}
+}
+
+func (d *dashboard) placeOrder(settings *api.TradeSettings) string {
+	trade, err := d.client.Private.Trade(settings)
+	if err == nil {
+		err = failed(trade.Success, trade.Error)
+	}
+	if err != nil {
+		return "order failed: " + err.Error()
+	}
+	if trade.Return.OrderID == 0 {
+		return fmt.Sprintf("order filled, received %s", formatFloat(trade.Return.Received))
+	}
+	return fmt.Sprintf("order %d placed, remains %s", trade.Return.OrderID, formatFloat(trade.Return.Remains))
+}
+
+func (d *dashboard) cancelOrder(id uint64) string {
+	cancel, err := d.client.Private.CancelOrder(&api.CancelOrderSettings{OrderID: id})
+	if err == nil {
+		err = failed(cancel.Success, cancel.Error)
+	}
+	if err != nil {
+		return "cancel failed: " + err.Error()
+	}
+	return fmt.Sprintf("order %d cancelled", id)
+}
+
+func (d *dashboard) setStatus(status string) {
+	d.mu.Lock()
+	d.status = status
+	d.mu.Unlock()
+	d.screen.PostEvent(tcell.NewEventInterrupt(nil))
+}
+
+// parseOrder parses "amount@rate"
+func parseOrder(pair, typ, input string) (*api.TradeSettings, error) {
+	parts := strings.Split(strings.TrimSpace(input), "@")
+	if len(parts) != 2 {
+		return nil, errors.New("enter the order as amount@rate")
+	}
+	amount, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || amount <= 0 {
+		return nil, errors.New("bad amount " + parts[0])
+	}
+	rate, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || rate <= 0 {
+		return nil, errors.New("bad rate " + parts[1])
+	}
+
+	return &api.TradeSettings{Pair: pair, Type: typ, Amount: amount, Rate: rate}, nil
+}
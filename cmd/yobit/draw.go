@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+var (
+	styleDefault  = tcell.StyleDefault
+	styleTitle    = tcell.StyleDefault.Bold(true)
+	styleSelected = tcell.StyleDefault.Reverse(true)
+	styleAsk      = tcell.StyleDefault.Foreground(tcell.ColorRed)
+	styleBid      = tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	styleStatus   = tcell.StyleDefault.Foreground(tcell.ColorYellow)
+)
+
+// rect is an area of the screen
+type rect struct {
+	x, y, w, h int
+}
+
+// draw renders the whole dashboard
+func (d *dashboard) draw() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s := d.screen
+	s.Clear()
+	w, h := s.Size()
+	if w < 60 || h < 16 {
+		text(s, 0, 0, w, styleDefault, "terminal is too small")
+		s.Show()
+		return
+	}
+
+	top := (h - 1) * 3 / 5
+	left := w * 2 / 5
+	bookW := (w - left) / 2
+
+	d.drawWatchlist(rect{0, 0, left, top})
+	d.drawBook(rect{left, 0, bookW, top})
+	d.drawTape(rect{left + bookW, 0, w - left - bookW, top})
+	d.drawBalances(rect{0, top, left, h - 1 - top})
+	d.drawOrders(rect{left, top, w - left, h - 1 - top})
+	d.drawStatus(rect{0, h - 1, w, 1})
+
+	s.Show()
+}
+
+func (d *dashboard) drawWatchlist(r rect) {
+	inner := box(d.screen, r, "Watchlist")
+	col := (inner.w - 10) / 3
+	text(d.screen, inner.x, inner.y, inner.w, styleTitle, fmt.Sprintf("%-9s %*s%*s%*s", "PAIR", col, "LAST", col, "BID", col, "ASK"))
+	for i, pair := range d.pairs {
+		if i+1 >= inner.h {
+			break
+		}
+		t := d.tickers[pair]
+		style := styleDefault
+		if i == d.selected {
+			style = styleSelected
+		}
+		text(d.screen, inner.x, inner.y+1+i, inner.w, style,
+			fmt.Sprintf("%-9s %*s%*s%*s", pair, col, price(t.Last), col, price(t.Buy), col, price(t.Sell)))
+	}
+}
+
+func (d *dashboard) drawBook(r rect) {
+	inner := box(d.screen, r, "Order book "+d.pairs[d.selected])
+	rows := (inner.h - 1) / 2
+	asks := d.book.Asks
+	if len(asks) > rows {
+		asks = asks[:rows]
+	}
+	// best ask right above the bids
+	y := inner.y + rows - len(asks)
+	for i := len(asks) - 1; i >= 0; i-- {
+		text(d.screen, inner.x, y, inner.w, styleAsk, fmt.Sprintf("%12s %12s", price(asks[i][0]), price(asks[i][1])))
+		y++
+	}
+	text(d.screen, inner.x, inner.y+rows, inner.w, styleTitle, fmt.Sprintf("%12s %12s", "PRICE", "AMOUNT"))
+	for i, bid := range d.book.Bids {
+		if i >= inner.h-rows-1 {
+			break
+		}
+		text(d.screen, inner.x, inner.y+rows+1+i, inner.w, styleBid, fmt.Sprintf("%12s %12s", price(bid[0]), price(bid[1])))
+	}
+}
+
+func (d *dashboard) drawTape(r rect) {
+	inner := box(d.screen, r, "Trades")
+	text(d.screen, inner.x, inner.y, inner.w, styleTitle, fmt.Sprintf("%-8s %12s %12s", "TIME", "PRICE", "AMOUNT"))
+	for i, t := range d.tape {
+		if i+1 >= inner.h {
+			break
+		}
+		style := styleBid
+		if t.Type == "ask" {
+			style = styleAsk
+		}
+		text(d.screen, inner.x, inner.y+1+i, inner.w, style,
+			fmt.Sprintf("%-8s %12s %12s", t.Timestamp.Format("15:04:05"), price(t.Price), price(t.Amount)))
+	}
+}
+
+func (d *dashboard) drawBalances(r rect) {
+	inner := box(d.screen, r, "Balances")
+	if !d.private {
+		text(d.screen, inner.x, inner.y, inner.w, styleDefault, "no API credentials")
+		return
+	}
+
+	coins := make([]string, 0, len(d.funds))
+	for coin, v := range d.funds {
+		if v != 0 {
+			coins = append(coins, coin)
+		}
+	}
+	sort.Strings(coins)
+	for i, coin := range coins {
+		if i >= inner.h {
+			break
+		}
+		text(d.screen, inner.x, inner.y+i, inner.w, styleDefault, fmt.Sprintf("%-8s %18s", coin, price(d.funds[coin])))
+	}
+}
+
+func (d *dashboard) drawOrders(r rect) {
+	inner := box(d.screen, r, "Open orders "+d.pairs[d.selected])
+	text(d.screen, inner.x, inner.y, inner.w, styleTitle, fmt.Sprintf("%-12s %-5s %14s %14s", "ID", "TYPE", "AMOUNT", "RATE"))
+	for i, o := range d.orders {
+		if i+1 >= inner.h {
+			break
+		}
+		style := styleDefault
+		if i == d.order {
+			style = styleSelected
+		}
+		text(d.screen, inner.x, inner.y+1+i, inner.w, style,
+			fmt.Sprintf("%-12d %-5s %14s %14s", o.id, o.typ, price(o.amount), price(o.rate)))
+	}
+}
+
+func (d *dashboard) drawStatus(r rect) {
+	if p := d.prompt; p != nil {
+		text(d.screen, r.x, r.y, r.w, styleStatus, p.label+p.input)
+		if !p.confirm {
+			d.screen.ShowCursor(r.x+len(p.label)+len(p.input), r.y)
+		}
+		return
+	}
+	d.screen.HideCursor()
+
+	help := "↑↓ pair  tab order  b buy  s sell  c cancel  r refresh  q quit"
+	updated := ""
+	if !d.updated.IsZero() {
+		updated = d.updated.Format("15:04:05") + "  "
+	}
+	text(d.screen, r.x, r.y, r.w, styleStatus, updated+d.status)
+	if len(help) < r.w-len(updated)-len(d.status)-2 {
+		text(d.screen, r.x+r.w-len([]rune(help)), r.y, len(help), styleDefault, help)
+	}
+}
+
+// box draws a frame with the title and returns the area inside it
+func box(s tcell.Screen, r rect, title string) rect {
+	for x := r.x; x < r.x+r.w; x++ {
+		s.SetContent(x, r.y, tcell.RuneHLine, nil, styleDefault)
+		s.SetContent(x, r.y+r.h-1, tcell.RuneHLine, nil, styleDefault)
+	}
+	for y := r.y; y < r.y+r.h; y++ {
+		s.SetContent(r.x, y, tcell.RuneVLine, nil, styleDefault)
+		s.SetContent(r.x+r.w-1, y, tcell.RuneVLine, nil, styleDefault)
+	}
+	s.SetContent(r.x, r.y, tcell.RuneULCorner, nil, styleDefault)
+	s.SetContent(r.x+r.w-1, r.y, tcell.RuneURCorner, nil, styleDefault)
+	s.SetContent(r.x, r.y+r.h-1, tcell.RuneLLCorner, nil, styleDefault)
+	s.SetContent(r.x+r.w-1, r.y+r.h-1, tcell.RuneLRCorner, nil, styleDefault)
+	text(s, r.x+2, r.y, r.w-4, styleTitle, " "+title+" ")
+
+	return rect{r.x + 1, r.y + 1, r.w - 2, r.h - 2}
+}
+
+// text draws the text clipped to the width
+func text(s tcell.Screen, x, y, w int, style tcell.Style, str string) {
+	i := 0
+	for _, c := range str {
+		if i >= w {
+			return
+		}
+		s.SetContent(x+i, y, c, nil, style)
+		i++
+	}
+}
+
+// price formats prices and amounts with up to 8 decimals
+func price(f float64) string {
+	if f == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.8g", f)
+}
@@ -0,0 +1,106 @@
+// Command yobit is a command-line client of the Yobit API.
+//
+// Usage:
+//
+//	yobit [-format table|json|csv] [-config file] <command> [flags] [args]
+//
+// Credentials are read from YOBIT_API_KEY and YOBIT_API_SECRET, or from a JSON
+// config file {"key": "...", "secret": "..."} (on default: yobit/config.json in
+// the user config directory). Mutating commands accept -dry-run.
+//
+// The dashboard command shows a live terminal view of a watchlist, the order book,
+// trades, balances and open orders, and places and cancels orders after confirmation.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// command is a subcommand of the tool
+type command struct {
+	usage   string
+	private bool // needs credentials
+	run     func(env *env, args []string) (*output, error)
+}
+
+var commands = map[string]command{
+	"dashboard":       {usage: "dashboard [-interval d] [-rate n] pair...", run: runDashboard},
+	"info":            {usage: "info [pair...]", run: runInfo},
+	"ticker":          {usage: "ticker pair...", run: runTicker},
+	"depth":           {usage: "depth [-limit n] pair", run: runDepth},
+	"trades":          {usage: "trades [-limit n] pair", run: runTrades},
+	"balance":         {usage: "balance [-all]", private: true, run: runBalance},
+	"orders":          {usage: "orders pair", private: true, run: runOrders},
+	"order":           {usage: "order id", private: true, run: runOrder},
+	"trade":           {usage: "trade [-dry-run] -pair p -type buy|sell -rate r -amount a", private: true, run: runTrade},
+	"cancel":          {usage: "cancel [-dry-run] id", private: true, run: runCancel},
+	"history":         {usage: "history -pair p [-count n] [-from-id id] [-since unix] [-end unix]", private: true, run: runHistory},
+	"deposit-address": {usage: "deposit-address [-new] coin", private: true, run: runDepositAddress},
+	"withdraw":        {usage: "withdraw [-dry-run] -coin c -amount a -address addr", private: true, run: runWithdraw},
+	"yobicode":        {usage: "yobicode create [-dry-run] -currency c -amount a | yobicode redeem [-dry-run] coupon", private: true, run: runYobicode},
+}
+
+func main() {
+	flags := flag.NewFlagSet("yobit", flag.ExitOnError)
+	format := flags.String("format", "table", "output format: table, json or csv")
+	config := flags.String("config", defaultConfigPath(), "config file with API credentials")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: yobit [-format table|json|csv] [-config file] <command> [flags] [args]")
+		fmt.Fprintln(flags.Output(), "\ncommands:")
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintln(flags.Output(), "  "+commands[name].usage)
+		}
+		fmt.Fprintln(flags.Output(), "\nflags:")
+		flags.PrintDefaults()
+	}
+	flags.Parse(os.Args[1:])
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "yobit: unknown command %q\n", flags.Arg(0))
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	err := run(cmd, *format, *config, flags.Args()[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "yobit:", err)
+		os.Exit(1)
+	}
+}
+
+func run(cmd command, format, config string, args []string) error {
+	write, ok := writers[strings.ToLower(format)]
+	if !ok {
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	creds, err := loadCredentials(config)
+	if err != nil {
+		return err
+	}
+	if cmd.private && (creds.Key == "" || creds.Secret == "") {
+		return errors.New("credentials are not set: use YOBIT_API_KEY and YOBIT_API_SECRET or a config file")
+	}
+
+	out, err := cmd.run(newEnv(creds), args)
+	if err != nil || out == nil {
+		return err
+	}
+
+	return write(os.Stdout, out)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// output is the result of a command: the response for JSON and rows for table and CSV
+type output struct {
+	value  interface{}
+	header []string
+	rows   [][]string
+}
+
+var writers = map[string]func(io.Writer, *output) error{
+	"table": writeTable,
+	"json":  writeJSON,
+	"csv":   writeCSV,
+}
+
+func writeTable(w io.Writer, out *output) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(out.header, "\t")))
+	for _, row := range out.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func writeJSON(w io.Writer, out *output) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out.value)
+}
+
+func writeCSV(w io.Writer, out *output) error {
+	cw := csv.NewWriter(w)
+	cw.Write(out.header)
+	cw.WriteAll(out.rows)
+	return cw.Error()
+}
+
+// dryRun describes a request that was not sent
+func dryRun(method string, settings interface{}) *output {
+	out := &output{
+		value:  map[string]interface{}{"dry_run": true, "method": method, "settings": settings},
+		header: []string{"field", "value"},
+		rows:   [][]string{{"method", method}},
+	}
+
+	data, _ := json.Marshal(settings)
+	var fields map[string]interface{}
+	json.Unmarshal(data, &fields)
+	for _, k := range sortedKeys(fields) {
+		out.rows = append(out.rows, []string{k, fmt.Sprint(fields[k])})
+	}
+
+	return out
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatValue formats an untyped response value, numbers without exponents
+func formatValue(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return formatFloat(t)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"sort"
+	"strconv"
+
+	api "github.com/vladivolo/yobit-api"
+	"github.com/vladivolo/yobit-api/yobicode"
+)
+
+// failed returns the error of a response with success 0
+func failed(success uint8, message string) error {
+	if success != 0 {
+		return nil
+	}
+	if message == "" {
+		message = "request failed"
+	}
+	return errors.New(message)
+}
+
+func runBalance(env *env, args []string) (*output, error) {
+	flags := flag.NewFlagSet("balance", flag.ContinueOnError)
+	all := flags.Bool("all", false, "show coins with zero balance")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	info, err := env.client.Private.GetInfo()
+	if err != nil {
+		return nil, err
+	}
+	if err := failed(info.Success, info.Error); err != nil {
+		return nil, err
+	}
+
+	coins := make(map[string]bool)
+	for coin := range info.Return.Funds {
+		coins[coin] = true
+	}
+	for coin := range info.Return.FundsInclOrders {
+		coins[coin] = true
+	}
+	var names []string
+	for coin := range coins {
+		if *all || info.Return.FundsInclOrders[coin] != 0 || info.Return.Funds[coin] != 0 {
+			names = append(names, coin)
+		}
+	}
+	sort.Strings(names)
+
+	out := &output{
+		value:  info.Return,
+		header: []string{"coin", "available", "incl_orders"},
+	}
+	for _, coin := range names {
+		out.rows = append(out.rows, []string{coin, formatFloat(info.Return.Funds[coin]), formatFloat(info.Return.FundsInclOrders[coin])})
+	}
+
+	return out, nil
+}
+
+// orderRows formats ActiveOrders and OrderInfo returns
+func orderRows(orders map[uint64]api.OrderData) *output {
+	ids := make([]uint64, 0, len(orders))
+	for id := range orders {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	out := &output{
+		value:  orders,
+		header: []string{"id", "pair", "type", "start_amount", "amount", "rate", "created", "status"},
+	}
+	for _, id := range ids {
+		o := orders[id]
+		out.rows = append(out.rows, []string{strconv.FormatUint(id, 10), o.Pair, o.Type,
+			formatFloat(o.StartAmount), formatFloat(o.Amount), formatFloat(o.Rate),
+			formatTime(o.TimestampCreated), strconv.Itoa(o.Status)})
+	}
+
+	return out
+}
+
+func runOrders(env *env, args []string) (*output, error) {
+	if len(args) != 1 {
+		return nil, errors.New("usage: yobit orders pair")
+	}
+
+	orders, err := env.client.Private.ActiveOrders(&api.ActiveOrdersSettings{Pair: args[0]})
+	if err != nil {
+		return nil, err
+	}
+	if err := failed(orders.Success, orders.Error); err != nil {
+		return nil, err
+	}
+
+	return orderRows(orders.Return), nil
+}
+
+func runOrder(env *env, args []string) (*output, error) {
+	if len(args) != 1 {
+		return nil, errors.New("usage: yobit order id")
+	}
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return nil, errors.New("bad order ID " + args[0])
+	}
+
+	order, err := env.client.Private.OrderInfo(&api.OrderInfoSettings{OrderID: id})
+	if err != nil {
+		return nil, err
+	}
+	if err := failed(order.Success, order.Error); err != nil {
+		return nil, err
+	}
+
+	return orderRows(order.Return), nil
+}
+
+func runTrade(env *env, args []string) (*output, error) {
+	flags := flag.NewFlagSet("trade", flag.ContinueOnError)
+	settings := &api.TradeSettings{}
+	flags.StringVar(&settings.Pair, "pair", "", "pair (example: ltc_btc)")
+	flags.StringVar(&settings.Type, "type", "", "buy or sell")
+	flags.Float64Var(&settings.Rate, "rate", 0, "price")
+	flags.Float64Var(&settings.Amount, "amount", 0, "amount in base currency")
+	dry := flags.Bool("dry-run", false, "print the order without sending it")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if settings.Pair == "" || (settings.Type != "buy" && settings.Type != "sell") || settings.Rate <= 0 || settings.Amount <= 0 {
+		return nil, errors.New("usage: yobit trade [-dry-run] -pair p -type buy|sell -rate r -amount a")
+	}
+	if *dry {
+		return dryRun("Trade", settings), nil
+	}
+
+	trade, err := env.client.Private.Trade(settings)
+	if err != nil {
+		return nil, err
+	}
+	if err := failed(trade.Success, trade.Error); err != nil {
+		return nil, err
+	}
+
+	return &output{
+		value:  trade.Return,
+		header: []string{"order_id", "received", "remains"},
+		rows: [][]string{{strconv.FormatUint(trade.Return.OrderID, 10),
+			formatFloat(trade.Return.Received), formatFloat(trade.Return.Remains)}},
+	}, nil
+}
+
+func runCancel(env *env, args []string) (*output, error) {
+	flags := flag.NewFlagSet("cancel", flag.ContinueOnError)
+	dry := flags.Bool("dry-run", false, "print the request without sending it")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() != 1 {
+		return nil, errors.New("usage: yobit cancel [-dry-run] id")
+	}
+	id, err := strconv.ParseUint(flags.Arg(0), 10, 64)
+	if err != nil {
+		return nil, errors.New("bad order ID " + flags.Arg(0))
+	}
+
+	settings := &api.CancelOrderSettings{OrderID: id}
+	if *dry {
+		return dryRun("CancelOrder", settings), nil
+	}
+
+	cancel, err := env.client.Private.CancelOrder(settings)
+	if err != nil {
+		return nil, err
+	}
+	if err := failed(cancel.Success, cancel.Error); err != nil {
+		return nil, err
+	}
+
+	return &output{
+		value:  cancel.Return,
+		header: []string{"order_id", "status"},
+		rows:   [][]string{{strconv.FormatUint(cancel.Return.OrderID, 10), "cancelled"}},
+	}, nil
+}
+
+func runHistory(env *env, args []string) (*output, error) {
+	flags := flag.NewFlagSet("history", flag.ContinueOnError)
+	settings := &api.TradeHistorySettings{Order: "DESC"}
+	flags.StringVar(&settings.Pair, "pair", "", "pair (example: ltc_btc)")
+	flags.Uint64Var(&settings.Count, "count", 0, "number of trades (on default 1000)")
+	flags.Uint64Var(&settings.FromID, "from-id", 0, "first trade ID")
+	flags.Uint64Var(&settings.Since, "since", 0, "start time (unix)")
+	flags.Uint64Var(&settings.End, "end", 0, "end time (unix)")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if settings.Pair == "" {
+		return nil, errors.New("usage: yobit history -pair p [-count n] [-from-id id] [-since unix] [-end unix]")
+	}
+
+	history, err := env.client.Private.TradeHistory(settings)
+	if err != nil {
+		return nil, err
+	}
+	if err := failed(history.Success, history.Error); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(history.Return))
+	for id := range history.Return {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, _ := strconv.ParseUint(ids[i], 10, 64)
+		b, _ := strconv.ParseUint(ids[j], 10, 64)
+		return a > b
+	})
+
+	out := &output{
+		value:  history.Return,
+		header: []string{"id", "time", "pair", "type", "amount", "rate", "order_id"},
+	}
+	for _, id := range ids {
+		t := history.Return[id]
+		out.rows = append(out.rows, []string{id, formatTime(t.Timestamp), t.Pair, t.Type,
+			formatFloat(t.Amount), formatFloat(t.Rate), strconv.FormatUint(t.OrderID, 10)})
+	}
+
+	return out, nil
+}
+
+func runDepositAddress(env *env, args []string) (*output, error) {
+	flags := flag.NewFlagSet("deposit-address", flag.ContinueOnError)
+	needNew := flags.Bool("new", false, "request a new address")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() != 1 {
+		return nil, errors.New("usage: yobit deposit-address [-new] coin")
+	}
+
+	settings := &api.GetDepositAddressSettings{CoinName: flags.Arg(0)}
+	if *needNew {
+		settings.NeedNew = 1
+	}
+
+	gda, err := env.client.Private.GetDepositAddress(settings)
+	if err != nil {
+		return nil, err
+	}
+	if err := failed(gda.Success, gda.Error); err != nil {
+		return nil, err
+	}
+
+	return &output{
+		value:  gda.Return,
+		header: []string{"coin", "address", "processed_amount"},
+		rows:   [][]string{{settings.CoinName, gda.Return.Address, formatFloat(gda.Return.ProcessedAmount)}},
+	}, nil
+}
+
+func runWithdraw(env *env, args []string) (*output, error) {
+	flags := flag.NewFlagSet("withdraw", flag.ContinueOnError)
+	settings := &api.WithdrawCoinsToAddressSettings{}
+	flags.StringVar(&settings.CoinName, "coin", "", "coin (example: btc)")
+	flags.Float64Var(&settings.Amount, "amount", 0, "amount to withdraw")
+	flags.StringVar(&settings.Address, "address", "", "destination address")
+	dry := flags.Bool("dry-run", false, "validate and print the withdrawal without sending it")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	if *dry {
+		if v := env.client.Private.AddressValidator; v != nil {
+			if err := v.ValidateAddress(settings.CoinName, settings.Address); err != nil {
+				return nil, err
+			}
+		}
+		return dryRun("WithdrawCoinsToAddress", settings), nil
+	}
+
+	resp, err := env.client.Private.WithdrawCoinsToAddress(settings)
+	if err != nil {
+		return nil, err
+	}
+	if err := failed(resp.Success, resp.Error); err != nil {
+		return nil, err
+	}
+
+	return &output{
+		value:  resp.Return,
+		header: []string{"coin", "amount", "address", "server_time"},
+		rows: [][]string{{settings.CoinName, formatFloat(settings.Amount), settings.Address,
+			formatTime(resp.Return.ServerTime)}},
+	}, nil
+}
+
+func runYobicode(env *env, args []string) (*output, error) {
+	if len(args) == 0 {
+		return nil, errors.New("usage: yobit yobicode create|redeem ...")
+	}
+
+	switch args[0] {
+	case "create":
+		flags := flag.NewFlagSet("yobicode create", flag.ContinueOnError)
+		settings := &api.CreateYobicodeSettings{}
+		flags.StringVar(&settings.Currency, "currency", "", "coin of the coupon (example: btc)")
+		flags.Float64Var(&settings.Amount, "amount", 0, "coupon amount")
+		dry := flags.Bool("dry-run", false, "print the request without sending it")
+		if err := flags.Parse(args[1:]); err != nil {
+			return nil, err
+		}
+		if settings.Currency == "" || settings.Amount <= 0 {
+			return nil, errors.New("usage: yobit yobicode create [-dry-run] -currency c -amount a")
+		}
+		if *dry {
+			return dryRun("CreateYobicode", settings), nil
+		}
+
+		resp, err := env.client.Private.CreateYobicode(settings)
+		if err != nil {
+			return nil, err
+		}
+		if err := failed(resp.Success, resp.Error); err != nil {
+			return nil, err
+		}
+
+		return &output{
+			value:  resp.Return,
+			header: []string{"coupon", "currency", "amount"},
+			rows:   [][]string{{resp.Return.Coupon, settings.Currency, formatFloat(settings.Amount)}},
+		}, nil
+
+	case "redeem":
+		flags := flag.NewFlagSet("yobicode redeem", flag.ContinueOnError)
+		dry := flags.Bool("dry-run", false, "validate the coupon without redeeming it")
+		if err := flags.Parse(args[1:]); err != nil {
+			return nil, err
+		}
+		if flags.NArg() != 1 {
+			return nil, errors.New("usage: yobit yobicode redeem [-dry-run] coupon")
+		}
+		settings := &api.RedeemYobicodeSettings{Coupon: flags.Arg(0)}
+		if err := yobicode.ValidateCoupon(settings.Coupon); err != nil {
+			return nil, err
+		}
+		if *dry {
+			return dryRun("RedeemYobicode", &api.RedeemYobicodeSettings{Coupon: "[REDACTED]"}), nil
+		}
+
+		resp, err := env.client.Private.RedeemYobicode(settings)
+		if err != nil {
+			return nil, err
+		}
+		if err := failed(resp.Success, resp.Error); err != nil {
+			return nil, err
+		}
+
+		return &output{
+			value:  resp.Return,
+			header: []string{"currency", "amount"},
+			rows:   [][]string{{resp.Return.CouponCurrency, formatFloat(resp.Return.CouponAmount)}},
+		}, nil
+	}
+
+	return nil, errors.New("usage: yobit yobicode create|redeem ...")
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"sort"
+	"strconv"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+func runInfo(env *env, args []string) (*output, error) {
+	info, err := env.client.Public.Info()
+	if err != nil {
+		return nil, err
+	}
+	if info.Success == 0 && info.Error != "" {
+		return nil, errors.New(info.Error)
+	}
+
+	pairs := args
+	if len(pairs) == 0 {
+		for pair := range info.Pairs {
+			pairs = append(pairs, pair)
+		}
+		sort.Strings(pairs)
+	}
+
+	out := &output{
+		value:  info,
+		header: []string{"pair", "decimal_places", "min_price", "max_price", "min_amount", "min_total", "fee", "hidden"},
+	}
+	for _, pair := range pairs {
+		p, ok := info.Pairs[pair]
+		if !ok {
+			return nil, errors.New("unknown pair " + pair)
+		}
+		row := []string{pair}
+		for _, field := range out.header[1:] {
+			row = append(row, formatValue(p[field]))
+		}
+		out.rows = append(out.rows, row)
+	}
+	if len(args) > 0 {
+		selected := make(map[string]map[string]interface{}, len(args))
+		for _, pair := range args {
+			selected[pair] = info.Pairs[pair]
+		}
+		out.value = selected
+	}
+
+	return out, nil
+}
+
+func runTicker(env *env, args []string) (*output, error) {
+	if len(args) == 0 {
+		return nil, errors.New("usage: yobit ticker pair...")
+	}
+
+	ticker, err := env.client.Public.Ticker(&api.TickerSettings{Pairs: args})
+	if err != nil {
+		return nil, err
+	}
+
+	out := &output{
+		value:  ticker.PairData,
+		header: []string{"pair", "last", "buy", "sell", "high", "low", "avg", "vol", "vol_cur", "updated"},
+	}
+	for _, pair := range args {
+		t := ticker.PairData[pair]
+		out.rows = append(out.rows, []string{pair,
+			formatFloat(t.Last), formatFloat(t.Buy), formatFloat(t.Sell), formatFloat(t.High), formatFloat(t.Low),
+			formatFloat(t.Avg), formatFloat(t.Vol), formatFloat(t.VolCur), formatTime(t.Updated)})
+	}
+
+	return out, nil
+}
+
+func runDepth(env *env, args []string) (*output, error) {
+	flags := flag.NewFlagSet("depth", flag.ContinueOnError)
+	limit := flags.Uint64("limit", 0, "number of orders of each side (on default 150, 2000 maximum)")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() != 1 {
+		return nil, errors.New("usage: yobit depth [-limit n] pair")
+	}
+	pair := flags.Arg(0)
+
+	depth, err := env.client.Public.Depth(&api.DepthSettings{Pair: pair, Limit: *limit})
+	if err != nil {
+		return nil, err
+	}
+
+	data := depth.PairData[pair]
+	out := &output{
+		value:  data,
+		header: []string{"side", "price", "amount"},
+	}
+	for _, ask := range data.Asks {
+		out.rows = append(out.rows, []string{"ask", formatFloat(ask[0]), formatFloat(ask[1])})
+	}
+	for _, bid := range data.Bids {
+		out.rows = append(out.rows, []string{"bid", formatFloat(bid[0]), formatFloat(bid[1])})
+	}
+
+	return out, nil
+}
+
+func runTrades(env *env, args []string) (*output, error) {
+	flags := flag.NewFlagSet("trades", flag.ContinueOnError)
+	limit := flags.Uint64("limit", 0, "number of trades (on default 150, 2000 maximum)")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() != 1 {
+		return nil, errors.New("usage: yobit trades [-limit n] pair")
+	}
+	pair := flags.Arg(0)
+
+	trades, err := env.client.Public.Trades(&api.TradesSettings{Pair: pair, Limit: *limit})
+	if err != nil {
+		return nil, err
+	}
+
+	data := trades.PairData[pair]
+	out := &output{
+		value:  data,
+		header: []string{"tid", "time", "type", "price", "amount"},
+	}
+	for _, t := range data {
+		out.rows = append(out.rows, []string{strconv.FormatUint(t.Tid, 10), formatTime(t.Timestamp), t.Type,
+			formatFloat(t.Price), formatFloat(t.Amount)})
+	}
+
+	return out, nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,423 @@
+// Package conditional implements client-side stop-loss, take-profit, trailing stop
+// and OCO orders on top of the Yobit API, which has no native conditional orders.
+package conditional
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// TickerSource provides prices watched by the Engine (e.g. *api.PublicAPI)
+type TickerSource interface {
+	Ticker(t *api.TickerSettings) (api.Ticker, error)
+}
+
+// Trader submits orders of fired triggers (e.g. *api.TradeAPI)
+type Trader interface {
+	Trade(t *api.TradeSettings) (api.Trade, error)
+}
+
+// Result describes a fired trigger
+type Result struct {
+	Trigger   Trigger   // trigger that fired
+	Price     float64   // price that fired the trigger
+	Trade     api.Trade // response of the submitted order
+	Cancelled []string  // linked OCO triggers removed together with this one
+	Err       error     // order submission error: after a rejection the trigger stays pending, otherwise it stays submitting until Resolve
+}
+
+// ErrSubmitting is returned for triggers whose order may have been placed
+var ErrSubmitting = errors.New("conditional: order of the trigger may have been placed, resolve it first")
+
+// RejectedError is an order of a fired trigger rejected by the API
+type RejectedError struct {
+	Message string
+}
+
+func (e *RejectedError) Error() string {
+	return "conditional: trade rejected: " + e.Message
+}
+
+// Engine watches the ticker and submits orders when triggers fire
+type Engine struct {
+	source TickerSource
+	trader Trader
+	store  Store
+
+	mu       sync.Mutex
+	triggers map[string]*Trigger
+
+	// Notify is called for every fired trigger, if set
+	Notify func(Result)
+}
+
+// NewEngine is a constructor for the Engine. Pending triggers are loaded from the store.
+func NewEngine(source TickerSource, trader Trader, store Store) (*Engine, error) {
+	engine := &Engine{
+		source:   source,
+		trader:   trader,
+		store:    store,
+		triggers: make(map[string]*Trigger),
+	}
+
+	if store != nil {
+		triggers, err := store.Load()
+		if err != nil {
+			return nil, err
+		}
+		for i := range triggers {
+			t := triggers[i]
+			engine.triggers[t.ID] = &t
+		}
+	}
+
+	return engine, nil
+}
+
+// Add registers a new trigger and returns its ID
+func (e *Engine) Add(t Trigger) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	err := e.add(&t)
+	if err != nil {
+		return "", err
+	}
+
+	return t.ID, e.save()
+}
+
+// AddOCO registers two triggers where firing one cancels the other
+func (e *Engine) AddOCO(a, b Trigger) (string, string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if a.ID == "" {
+		a.ID = newID()
+	}
+	if b.ID == "" {
+		b.ID = newID()
+	}
+	a.OCO = b.ID
+	b.OCO = a.ID
+
+	err := e.add(&a)
+	if err != nil {
+		return "", "", err
+	}
+	err = e.add(&b)
+	if err != nil {
+		delete(e.triggers, a.ID)
+		return "", "", err
+	}
+
+	return a.ID, b.ID, e.save()
+}
+
+// Cancel removes a pending trigger together with its OCO pair
+func (e *Engine) Cancel(id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	t, ok := e.triggers[id]
+	if !ok {
+		return fmt.Errorf("conditional: trigger %s not found", id)
+	}
+	if e.submitting(t) {
+		return ErrSubmitting
+	}
+	delete(e.triggers, id)
+	if t.OCO != "" {
+		delete(e.triggers, t.OCO)
+	}
+
+	return e.save()
+}
+
+// Resolve settles a trigger left submitting by an error with unknown outcome (e.g. a timeout
+// or a crash) after checking the account: a placed order removes the trigger and its OCO pair,
+// otherwise the trigger is pending again.
+func (e *Engine) Resolve(id string, placed bool) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	t, ok := e.triggers[id]
+	if !ok {
+		return fmt.Errorf("conditional: trigger %s not found", id)
+	}
+	if t.Submitting.IsZero() {
+		return fmt.Errorf("conditional: trigger %s isn't submitting", id)
+	}
+
+	if placed {
+		delete(e.triggers, id)
+		if t.OCO != "" {
+			delete(e.triggers, t.OCO)
+		}
+	} else {
+		t.Submitting = time.Time{}
+	}
+
+	return e.save()
+}
+
+// Pending returns copies of all pending triggers ordered by creation time
+func (e *Engine) Pending() []Trigger {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	triggers := make([]Trigger, 0, len(e.triggers))
+	for _, t := range e.triggers {
+		triggers = append(triggers, *t)
+	}
+	sort.Slice(triggers, func(i, j int) bool {
+		return triggers[i].Created.Before(triggers[j].Created)
+	})
+
+	return triggers
+}
+
+// Run checks triggers every interval until the context is done
+func (e *Engine) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := e.Check()
+		if err != nil && e.Notify != nil {
+			e.Notify(Result{Err: err})
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check loads the ticker for all pending pairs once and submits orders of fired triggers.
+// Fired triggers are marked submitting and saved before their orders are sent, the lock
+// isn't held while sending.
+func (e *Engine) Check() ([]Result, error) {
+	pairs := e.pairs()
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	tickers, err := api.Tickers(e.source, pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	fired, prices, err := e.fire(tickers)
+	if err != nil || len(fired) == 0 {
+		return nil, err
+	}
+
+	results := make([]Result, len(fired))
+	for i, t := range fired {
+		results[i] = Result{Trigger: t, Price: prices[i]}
+		results[i].Trade, results[i].Err = e.submit(&t)
+	}
+
+	err = e.settle(results)
+	for _, r := range results {
+		if e.Notify != nil {
+			e.Notify(r)
+		}
+	}
+
+	return results, err
+}
+
+// fire updates the triggers with the ticker and marks the fired ones submitting
+func (e *Engine) fire(tickers map[string]api.TData) ([]Trigger, []float64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var fired []*Trigger
+	var prices []float64
+	for _, t := range e.triggers {
+		if e.submitting(t) {
+			continue
+		}
+		data, ok := tickers[t.Order.Pair]
+		if !ok {
+			continue
+		}
+		price := t.price(data)
+		if t.update(price) {
+			fired = append(fired, t)
+			prices = append(prices, price)
+		}
+	}
+
+	var marked []Trigger
+	var markedPrices []float64
+	now := time.Now()
+	for i, t := range fired {
+		if e.submitting(t) {
+			continue // its OCO pair fired during this check
+		}
+		t.Submitting = now
+		marked = append(marked, *t)
+		markedPrices = append(markedPrices, prices[i])
+	}
+
+	err := e.save()
+	if err != nil {
+		// nothing is sent unless the marks are persisted
+		for _, t := range marked {
+			e.triggers[t.ID].Submitting = time.Time{}
+		}
+		return nil, nil, err
+	}
+
+	return marked, markedPrices, nil
+}
+
+// settle removes the triggers of placed orders and clears the marks of rejected ones
+func (e *Engine) settle(results []Result) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for i := range results {
+		r := &results[i]
+		t, ok := e.triggers[r.Trigger.ID]
+		if !ok {
+			continue
+		}
+
+		var rejected *RejectedError
+		switch {
+		case r.Err == nil:
+			delete(e.triggers, t.ID)
+			if t.OCO != "" {
+				if _, ok := e.triggers[t.OCO]; ok {
+					delete(e.triggers, t.OCO)
+					r.Cancelled = append(r.Cancelled, t.OCO)
+				}
+			}
+		case errors.As(r.Err, &rejected):
+			t.Submitting = time.Time{}
+			r.Trigger.Submitting = time.Time{}
+		}
+		// other errors leave the trigger submitting, the order may have been placed
+	}
+
+	return e.save()
+}
+
+// submit sends the order of a fired trigger
+func (e *Engine) submit(t *Trigger) (api.Trade, error) {
+	order := t.Order
+	trade, err := e.trader.Trade(&order)
+	if err != nil {
+		return trade, err
+	}
+	if trade.Success == 0 {
+		return trade, &RejectedError{Message: trade.Error}
+	}
+
+	return trade, nil
+}
+
+// submitting reports whether the order of the trigger or of its OCO pair may have been sent
+func (e *Engine) submitting(t *Trigger) bool {
+	if !t.Submitting.IsZero() {
+		return true
+	}
+	if oco, ok := e.triggers[t.OCO]; ok && !oco.Submitting.IsZero() {
+		return true
+	}
+	return false
+}
+
+// pairs returns the distinct pairs of pending triggers
+func (e *Engine) pairs() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var pairs []string
+	for _, t := range e.triggers {
+		if !seen[t.Order.Pair] {
+			seen[t.Order.Pair] = true
+			pairs = append(pairs, t.Order.Pair)
+		}
+	}
+	sort.Strings(pairs)
+
+	return pairs
+}
+
+// add validates a trigger and stores it in memory
+func (e *Engine) add(t *Trigger) error {
+	if t.Order.Pair == "" {
+		return errors.New("conditional: order pair hasn't been set")
+	}
+	if t.Order.Type != "buy" && t.Order.Type != "sell" {
+		return fmt.Errorf("conditional: unknown order type %q", t.Order.Type)
+	}
+	switch t.Kind {
+	case StopLoss, TakeProfit:
+		if t.Price <= 0 {
+			return errors.New("conditional: trigger price hasn't been set")
+		}
+	case TrailingStop:
+		if t.TrailDelta <= 0 && t.TrailPercent <= 0 {
+			return errors.New("conditional: trailing distance hasn't been set")
+		}
+	default:
+		return fmt.Errorf("conditional: unknown trigger kind %q", t.Kind)
+	}
+
+	if t.ID == "" {
+		t.ID = newID()
+	}
+	if _, ok := e.triggers[t.ID]; ok {
+		return fmt.Errorf("conditional: trigger %s already exists", t.ID)
+	}
+	if t.Field == "" {
+		t.Field = Last
+	}
+	if t.Created.IsZero() {
+		t.Created = time.Now()
+	}
+	e.triggers[t.ID] = t
+
+	return nil
+}
+
+// save persists pending triggers
+func (e *Engine) save() error {
+	if e.store == nil {
+		return nil
+	}
+
+	triggers := make([]Trigger, 0, len(e.triggers))
+	for _, t := range e.triggers {
+		triggers = append(triggers, *t)
+	}
+	sort.Slice(triggers, func(i, j int) bool {
+		return triggers[i].Created.Before(triggers[j].Created)
+	})
+
+	return e.store.Save(triggers)
+}
+
+// newID returns a random trigger ID
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,158 @@
+package conditional
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+type fakeTicker struct {
+	prices map[string]float64
+}
+
+func (f *fakeTicker) Ticker(t *api.TickerSettings) (api.Ticker, error) {
+	ticker := api.Ticker{Success: 1, PairData: make(map[string]api.TData)}
+	for _, pair := range t.Pairs {
+		if p, ok := f.prices[pair]; ok {
+			ticker.PairData[pair] = api.TData{Last: p, Buy: p, Sell: p}
+		}
+	}
+	return ticker, nil
+}
+
+type fakeTrader struct {
+	calls  []api.TradeSettings
+	result func(n int) (api.Trade, error)
+}
+
+func (f *fakeTrader) Trade(t *api.TradeSettings) (api.Trade, error) {
+	f.calls = append(f.calls, *t)
+	if f.result != nil {
+		return f.result(len(f.calls))
+	}
+	return api.Trade{Success: 1, Return: api.TradeReturn{OrderID: uint64(len(f.calls))}}, nil
+}
+
+func order(typ string) api.TradeSettings {
+	return api.TradeSettings{Pair: "ltc_btc", Type: typ, Rate: 1, Amount: 1}
+}
+
+func TestTriggerUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		trigger Trigger
+		prices  []float64
+		fired   []bool
+	}{
+		{"sell stop loss", Trigger{Kind: StopLoss, Price: 90, Order: order("sell")},
+			[]float64{100, 91, 90}, []bool{false, false, true}},
+		{"buy stop loss", Trigger{Kind: StopLoss, Price: 110, Order: order("buy")},
+			[]float64{100, 109, 111}, []bool{false, false, true}},
+		{"sell take profit", Trigger{Kind: TakeProfit, Price: 110, Order: order("sell")},
+			[]float64{100, 110}, []bool{false, true}},
+		{"buy take profit", Trigger{Kind: TakeProfit, Price: 90, Order: order("buy")},
+			[]float64{100, 89}, []bool{false, true}},
+		{"zero price is ignored", Trigger{Kind: StopLoss, Price: 90, Order: order("sell")},
+			[]float64{0}, []bool{false}},
+		{"sell trailing delta", Trigger{Kind: TrailingStop, TrailDelta: 10, Order: order("sell")},
+			[]float64{100, 120, 111, 110}, []bool{false, false, false, true}},
+		{"buy trailing percent", Trigger{Kind: TrailingStop, TrailPercent: 10, Order: order("buy")},
+			[]float64{100, 80, 87, 88}, []bool{false, false, false, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trigger := tt.trigger
+			for i, price := range tt.prices {
+				if got := trigger.update(price); got != tt.fired[i] {
+					t.Fatalf("update(%v) = %v, want %v", price, got, tt.fired[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEngineCheck(t *testing.T) {
+	uncertain := errors.New("timeout")
+
+	tests := []struct {
+		name       string
+		result     func(n int) (api.Trade, error)
+		pending    int  // triggers left after the first check
+		submitting bool // the stop is left submitting
+		calls      int  // orders sent after all checks
+	}{
+		{"placed", nil, 0, false, 1},
+		{"rejected stays pending and fires again", func(int) (api.Trade, error) {
+			return api.Trade{Success: 0, Error: "Insufficient funds"}, nil
+		}, 2, false, 3},
+		{"unknown outcome isn't resent", func(int) (api.Trade, error) {
+			return api.Trade{}, uncertain
+		}, 2, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &fakeTicker{prices: map[string]float64{"ltc_btc": 100}}
+			trader := &fakeTrader{result: tt.result}
+			store := NewFileStore(filepath.Join(t.TempDir(), "triggers.json"))
+			e, err := NewEngine(source, trader, store)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			stop, _, err := e.AddOCO(
+				Trigger{Kind: StopLoss, Price: 90, Order: order("sell")},
+				Trigger{Kind: TakeProfit, Price: 120, Order: order("sell")},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			source.prices["ltc_btc"] = 85
+			results, err := e.Check()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 1 || results[0].Trigger.ID != stop {
+				t.Fatalf("results = %+v, want the stop only", results)
+			}
+			if len(e.Pending()) != tt.pending {
+				t.Fatalf("pending = %d, want %d", len(e.Pending()), tt.pending)
+			}
+
+			// the take profit must not fire while the stop may have been placed
+			source.prices["ltc_btc"] = 130
+			e.Check()
+			source.prices["ltc_btc"] = 85
+			e.Check()
+			if len(trader.calls) != tt.calls {
+				t.Fatalf("orders sent = %d, want %d", len(trader.calls), tt.calls)
+			}
+
+			// the state survives a restart
+			restarted, err := NewEngine(source, trader, store)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, trigger := range restarted.Pending() {
+				if trigger.ID == stop && !trigger.Submitting.IsZero() != tt.submitting {
+					t.Fatalf("submitting = %v, want %v", trigger.Submitting, tt.submitting)
+				}
+			}
+			if tt.submitting {
+				if err := restarted.Cancel(stop); err != ErrSubmitting {
+					t.Fatalf("Cancel = %v, want ErrSubmitting", err)
+				}
+				if err := restarted.Resolve(stop, true); err != nil {
+					t.Fatal(err)
+				}
+				if len(restarted.Pending()) != 0 {
+					t.Fatalf("pending after Resolve = %d, want 0", len(restarted.Pending()))
+				}
+			}
+		})
+	}
+}
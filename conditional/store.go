@@ -0,0 +1,72 @@
+package conditional
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Store keeps pending triggers between restarts
+type Store interface {
+	Load() ([]Trigger, error)
+	Save(triggers []Trigger) error
+}
+
+// FileStore is a Store that keeps triggers in a JSON file
+type FileStore struct {
+	Path string
+}
+
+// NewFileStore is a constructor for the FileStore
+func NewFileStore(path string) *FileStore {
+	return &FileStore{
+		Path: path,
+	}
+}
+
+// Load reads triggers from the file. A missing file means no triggers.
+func (s *FileStore) Load() ([]Trigger, error) {
+	data, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var triggers []Trigger
+	err = json.Unmarshal(data, &triggers)
+	if err != nil {
+		return nil, err
+	}
+
+	return triggers, nil
+}
+
+// Save writes triggers to a temporary file and moves it over the old one
+func (s *FileStore) Save(triggers []Trigger) error {
+	data, err := json.MarshalIndent(triggers, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.Path), filepath.Base(s.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.Path)
+}
@@ -0,0 +1,100 @@
+package conditional
+
+import (
+	"time"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// Kind is a type of conditional order
+type Kind string
+
+const (
+	StopLoss     Kind = "stop_loss"     // fires when price moves against the order
+	TakeProfit   Kind = "take_profit"   // fires when price moves in favour of the order
+	TrailingStop Kind = "trailing_stop" // stop loss that follows the best price seen
+)
+
+// PriceField selects which ticker price is compared with the trigger
+type PriceField string
+
+const (
+	Last PriceField = "last" // last transaction price
+	Buy  PriceField = "buy"  // buying price
+	Sell PriceField = "sell" // selling price
+)
+
+// Trigger is a pending conditional order
+type Trigger struct {
+	ID           string            `json:"id"`            // trigger ID (set by the Engine if empty)
+	Kind         Kind              `json:"kind"`          // stop_loss, take_profit or trailing_stop
+	Field        PriceField        `json:"field"`         // ticker price to watch (on default: last)
+	Price        float64           `json:"price"`         // trigger price for stop_loss and take_profit
+	TrailDelta   float64           `json:"trail_delta"`   // trailing distance in quote currency
+	TrailPercent float64           `json:"trail_percent"` // trailing distance in percent of the best price (used if TrailDelta is 0)
+	Extreme      float64           `json:"extreme"`       // best price seen by a trailing stop
+	Order        api.TradeSettings `json:"order"`         // order submitted when the trigger fires
+	OCO          string            `json:"oco"`           // ID of the linked trigger cancelled when this one fires
+	Created      time.Time         `json:"created"`       // creation time
+	Submitting   time.Time         `json:"submitting"`    // time the order was sent with an unknown outcome (zero = not sent)
+}
+
+// price returns the watched price from the ticker data
+func (t *Trigger) price(d api.TData) float64 {
+	switch t.Field {
+	case Buy:
+		return d.Buy
+	case Sell:
+		return d.Sell
+	default:
+		return d.Last
+	}
+}
+
+// selling reports whether the trigger closes a long position
+func (t *Trigger) selling() bool {
+	return t.Order.Type == "sell"
+}
+
+// stopPrice returns the current stop level of a trailing stop
+func (t *Trigger) stopPrice() float64 {
+	delta := t.TrailDelta
+	if delta == 0 {
+		delta = t.Extreme * t.TrailPercent / 100
+	}
+	if t.selling() {
+		return t.Extreme - delta
+	}
+	return t.Extreme + delta
+}
+
+// update moves the trailing extreme and reports whether the trigger has fired
+func (t *Trigger) update(price float64) bool {
+	if price <= 0 {
+		return false
+	}
+
+	switch t.Kind {
+	case StopLoss:
+		if t.selling() {
+			return price <= t.Price
+		}
+		return price >= t.Price
+	case TakeProfit:
+		if t.selling() {
+			return price >= t.Price
+		}
+		return price <= t.Price
+	case TrailingStop:
+		if t.Extreme == 0 || (t.selling() && price > t.Extreme) || (!t.selling() && price < t.Extreme) {
+			t.Extreme = price
+			return false
+		}
+		if t.selling() {
+			return price <= t.stopPrice()
+		}
+		return price >= t.stopPrice()
+	}
+
+	return false
+}
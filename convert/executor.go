@@ -0,0 +1,190 @@
+package convert
+
+import (
+	"fmt"
+	"math"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// Trader executes the legs of a conversion (e.g. *api.TradeAPI)
+type Trader interface {
+	GetInfo() (api.GetInfo, error)
+	Trade(t *api.TradeSettings) (api.Trade, error)
+	CancelOrder(t *api.CancelOrderSettings) (api.CancelOrder, error)
+}
+
+// LegResult describes one executed leg
+type LegResult struct {
+	Edge     Edge    // converted edge
+	Given    float64 // amount of From given
+	Received float64 // amount of To received, also set for a failed leg that was partially filled
+	Left     float64 // amount of From back on the balance after the unfilled rest was cancelled
+	OrderID  uint64  // order ID, 0 if the order was filled at once
+	Err      error   // leg error
+}
+
+// Report describes a conversion
+type Report struct {
+	From     string      // coin converted
+	Amount   float64     // amount converted
+	Legs     []LegResult // executed legs, the last one failed if Err is set
+	Holding  string      // coin held after the conversion stopped
+	Held     float64     // amount of Holding received by the conversion
+	Left     float64     // amount of the previous coin left by a partially filled failed leg
+	Complete bool        // all legs were executed
+	Err      error       // error that stopped the conversion
+}
+
+// Rollback returns the path that converts the Holding back to the From coin
+// along the legs executed so far, including a partially filled failed one, or nil
+// if there is nothing to roll back
+func (r Report) Rollback() Path {
+	var path Path
+	for i := len(r.Legs) - 1; i >= 0; i-- {
+		leg := r.Legs[i]
+		if leg.Received <= 0 {
+			continue
+		}
+		e := leg.Edge
+		reverse := Edge{Pair: e.Pair, From: e.To, To: e.From, Fee: e.Fee}
+		if e.Type == "sell" {
+			reverse.Type = "buy"
+		} else {
+			reverse.Type = "sell"
+		}
+		path = append(path, reverse)
+	}
+	return path
+}
+
+// Executor performs multi-leg conversions
+type Executor struct {
+	trader Trader
+
+	// Slippage is the allowed price deviation from the edge price (example: 0.005 for 0.5%)
+	Slippage float64
+	// Precision is the number of decimal places of order amounts (on default: 8)
+	Precision int
+}
+
+// NewExecutor is a constructor for the Executor
+func NewExecutor(trader Trader) *Executor {
+	return &Executor{
+		trader:    trader,
+		Precision: 8,
+	}
+}
+
+// Execute converts amount of the first coin of the path leg by leg. Every leg is sent
+// as a limit order at the edge price adjusted by Slippage; the unfilled rest of a leg
+// is cancelled and the received amount is carried to the next leg. If a leg fails
+// the report tells which coin and amount are held, and Report.Rollback gives the way back.
+func (x *Executor) Execute(path Path, amount float64) (Report, error) {
+	report := Report{Amount: amount}
+	if len(path) == 0 {
+		report.Err = fmt.Errorf("convert: empty path")
+		return report, report.Err
+	}
+	report.From = path[0].From
+	report.Holding, report.Held = path[0].From, amount
+
+	balance, err := x.trader.GetInfo()
+	if err == nil && balance.Success == 0 {
+		err = fmt.Errorf("convert: getInfo: %s", balance.Error)
+	}
+	if err != nil {
+		report.Err = err
+		return report, err
+	}
+	funds := balance.Return.Funds
+
+	for _, e := range path {
+		leg := LegResult{Edge: e, Given: report.Held}
+		var after map[string]float64
+		after, leg.OrderID, leg.Err = x.leg(e, report.Held)
+		if after != nil {
+			// the balances tell what the leg received whatever happened to the order
+			leg.Received = after[e.To] - funds[e.To]
+			if leg.OrderID != 0 {
+				leg.Left = math.Max(leg.Given-(funds[e.From]-after[e.From]), 0)
+			}
+			if leg.Err == nil && leg.Received <= 0 {
+				leg.Err = fmt.Errorf("convert: nothing received on %s", e.Pair)
+			}
+		}
+		report.Legs = append(report.Legs, leg)
+		if leg.Received > 0 {
+			report.Holding, report.Held = e.To, leg.Received
+			report.Left = leg.Left
+		}
+		if leg.Err != nil {
+			report.Err = leg.Err
+			return report, report.Err
+		}
+
+		funds = after
+		report.Left = 0
+	}
+	report.Complete = true
+
+	return report, nil
+}
+
+// leg sends the order of one edge and returns balances after it. The unfilled rest
+// of the order is cancelled; whatever the outcome of the cancel, the balances are
+// loaded so that a partial fill is never lost. The balances are nil if the order
+// wasn't placed or they couldn't be loaded.
+func (x *Executor) leg(e Edge, given float64) (map[string]float64, uint64, error) {
+	if e.Price <= 0 {
+		return nil, 0, fmt.Errorf("convert: no price for %s", e.Pair)
+	}
+
+	settings := &api.TradeSettings{
+		Pair: e.Pair,
+		Type: e.Type,
+	}
+	if e.Type == "sell" {
+		settings.Rate = e.Price * (1 - x.Slippage)
+		settings.Amount = x.round(given)
+	} else {
+		settings.Rate = e.Price * (1 + x.Slippage)
+		settings.Amount = x.round(given / settings.Rate)
+	}
+	if settings.Amount <= 0 {
+		return nil, 0, fmt.Errorf("convert: amount too small for %s", e.Pair)
+	}
+
+	trade, err := x.trader.Trade(settings)
+	if err != nil {
+		return nil, 0, err
+	}
+	if trade.Success == 0 {
+		return nil, 0, fmt.Errorf("convert: %s: %s", e.Pair, trade.Error)
+	}
+	if trade.Return.OrderID == 0 || trade.Return.Remains == 0 {
+		return trade.Return.Funds, trade.Return.OrderID, nil
+	}
+
+	// a failed cancel (e.g. the order got filled meanwhile) isn't a failed leg by
+	// itself, the balances decide; its error only counts if they can't be loaded
+	_, cancelErr := x.trader.CancelOrder(&api.CancelOrderSettings{OrderID: trade.Return.OrderID})
+	balance, err := x.trader.GetInfo()
+	if err == nil && balance.Success == 0 {
+		err = fmt.Errorf("convert: getInfo: %s", balance.Error)
+	}
+	if err != nil {
+		if cancelErr != nil {
+			err = cancelErr
+		}
+		return nil, trade.Return.OrderID, err
+	}
+
+	return balance.Return.Funds, trade.Return.OrderID, nil
+}
+
+// round rounds the amount down to Precision decimal places
+func (x *Executor) round(amount float64) float64 {
+	p := math.Pow10(x.Precision)
+	return math.Floor(amount*p) / p
+}
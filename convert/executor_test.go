@@ -0,0 +1,152 @@
+package convert
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+
+	api "github.com/vladivolo/yobit-api"
+	"github.com/vladivolo/yobit-api/mock"
+)
+
+// fakeTrader sells ltc for btc at 0.01: filled is the part of the order filled
+// before the cancel, the rest is cancelled unless cancel fails
+type fakeTrader struct {
+	funds     map[string]float64
+	filled    float64
+	cancel    api.CancelOrder
+	cancelErr error
+	fillLater bool // the order gets filled by the time of the cancel
+}
+
+func (f *fakeTrader) GetInfo() (api.GetInfo, error) {
+	funds := make(map[string]float64, len(f.funds))
+	for coin, v := range f.funds {
+		funds[coin] = v
+	}
+	return api.GetInfo{Success: 1, Return: api.InfoReturn{Funds: funds}}, nil
+}
+
+func (f *fakeTrader) Trade(t *api.TradeSettings) (api.Trade, error) {
+	f.funds["ltc"] -= t.Amount
+	f.funds["btc"] += t.Amount * f.filled * 0.01
+	trade := api.Trade{Success: 1}
+	trade.Return.Received = t.Amount * f.filled
+	trade.Return.Remains = t.Amount * (1 - f.filled)
+	if trade.Return.Remains > 0 {
+		trade.Return.OrderID = 7
+	}
+	trade.Return.Funds = f.funds
+	f.funds = copyFunds(f.funds)
+	return trade, nil
+}
+
+func (f *fakeTrader) CancelOrder(t *api.CancelOrderSettings) (api.CancelOrder, error) {
+	rest := 10 * (1 - f.filled)
+	switch {
+	case f.fillLater:
+		f.funds["btc"] += rest * 0.01
+	case f.cancelErr == nil && f.cancel.Success != 0:
+		f.funds["ltc"] += rest
+	}
+	return f.cancel, f.cancelErr
+}
+
+func copyFunds(funds map[string]float64) map[string]float64 {
+	c := make(map[string]float64, len(funds))
+	for coin, v := range funds {
+		c[coin] = v
+	}
+	return c
+}
+
+func TestExecuteLeg(t *testing.T) {
+	timeout := errors.New("timeout")
+
+	tests := []struct {
+		name     string
+		trader   fakeTrader
+		err      bool
+		received float64
+		left     float64
+		holding  string
+		rollback int
+	}{
+		{"filled at once", fakeTrader{filled: 1}, false, 0.1, 0, "btc", 1},
+		{"partially filled and cancelled", fakeTrader{filled: 0.4, cancel: api.CancelOrder{Success: 1}},
+			false, 0.04, 6, "btc", 1},
+		{"cancel failed after a partial fill", fakeTrader{filled: 0.4, cancelErr: timeout},
+			false, 0.04, 0, "btc", 1},
+		{"cancel rejected, filled meanwhile", fakeTrader{filled: 0.4, fillLater: true,
+			cancel: api.CancelOrder{Success: 0, Error: "order not found"}}, false, 0.1, 0, "btc", 1},
+		{"nothing filled", fakeTrader{filled: 0, cancel: api.CancelOrder{Success: 1}},
+			true, 0, 10, "ltc", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trader := tt.trader
+			trader.funds = map[string]float64{"ltc": 10}
+			path := Path{{Pair: "ltc_btc", From: "ltc", To: "btc", Type: "sell", Price: 0.01}}
+
+			report, err := NewExecutor(&trader).Execute(path, 10)
+			if (err != nil) != tt.err {
+				t.Fatalf("err = %v, want error %v", err, tt.err)
+			}
+
+			leg := report.Legs[0]
+			if math.Abs(leg.Received-tt.received) > 1e-9 || math.Abs(leg.Left-tt.left) > 1e-9 {
+				t.Fatalf("received %v left %v, want %v and %v", leg.Received, leg.Left, tt.received, tt.left)
+			}
+			if report.Holding != tt.holding {
+				t.Fatalf("holding = %s, want %s", report.Holding, tt.holding)
+			}
+			if len(report.Rollback()) != tt.rollback {
+				t.Fatalf("rollback = %v, want %d legs", report.Rollback(), tt.rollback)
+			}
+		})
+	}
+}
+
+func TestExecuteCancelFailedFilled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	trading := mock.NewMockTrading(ctrl)
+
+	info := func(funds map[string]float64) api.GetInfo {
+		return api.GetInfo{Success: 1, Return: api.InfoReturn{Funds: funds}}
+	}
+	placed := api.Trade{Success: 1}
+	placed.Return.Remains = 10
+	placed.Return.OrderID = 7
+	placed.Return.Funds = map[string]float64{"ltc": 0, "btc": 0}
+
+	gomock.InOrder(
+		trading.EXPECT().GetInfo().Return(info(map[string]float64{"ltc": 10, "btc": 0}), nil),
+		trading.EXPECT().Trade(gomock.Any()).Return(placed, nil),
+		// the order got filled before the cancel arrived
+		trading.EXPECT().CancelOrder(&api.CancelOrderSettings{OrderID: 7}).Return(api.CancelOrder{}, errors.New("timeout")),
+		trading.EXPECT().GetInfo().Return(info(map[string]float64{"ltc": 0, "btc": 0.1}), nil),
+		trading.EXPECT().Trade(gomock.Any()).DoAndReturn(func(s *api.TradeSettings) (api.Trade, error) {
+			if s.Pair != "btc_usd" || math.Abs(s.Amount-0.1) > 1e-9 {
+				t.Fatalf("second leg = %+v, want a sell of 0.1 btc", s)
+			}
+			filled := api.Trade{Success: 1}
+			filled.Return.Funds = map[string]float64{"ltc": 0, "btc": 0, "usd": 2000}
+			return filled, nil
+		}),
+	)
+
+	path := Path{
+		{Pair: "ltc_btc", From: "ltc", To: "btc", Type: "sell", Price: 0.01},
+		{Pair: "btc_usd", From: "btc", To: "usd", Type: "sell", Price: 20000},
+	}
+	report, err := NewExecutor(trading).Execute(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Complete || report.Holding != "usd" || report.Held != 2000 {
+		t.Fatalf("report = %+v, want 2000 usd", report)
+	}
+}
@@ -0,0 +1,279 @@
+// Package convert builds a currency graph from Yobit pairs and converts coins
+// through the best path of one or more trades.
+package convert
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// MaxHops is the longest path searched by the Graph
+const MaxHops = 3
+
+// Edge is a conversion from one coin to another through a pair
+type Edge struct {
+	Pair  string  // pair (example: ltc_btc)
+	From  string  // coin given
+	To    string  // coin received
+	Type  string  // order type: sell if From is the base currency, buy otherwise
+	Price float64 // order price in quote currency
+	Fee   float64 // pair fee in percent
+	Rate  float64 // amount of To received for one From after fee (0 - no price yet)
+}
+
+// Path is a sequence of edges
+type Path []Edge
+
+// Rate returns the amount of the last coin received for one first coin
+func (p Path) Rate() float64 {
+	rate := 1.0
+	for _, e := range p {
+		rate *= e.Rate
+	}
+	return rate
+}
+
+// String returns the path as a list of coins (example: doge>ltc>btc)
+func (p Path) String() string {
+	if len(p) == 0 {
+		return ""
+	}
+	coins := []string{p[0].From}
+	for _, e := range p {
+		coins = append(coins, e.To)
+	}
+	return strings.Join(coins, ">")
+}
+
+// Graph is a currency graph built from active pairs
+type Graph struct {
+	mu    sync.RWMutex
+	edges map[string][]*Edge // edges by From coin
+	pairs map[string][2]*Edge
+}
+
+// NewGraph is a constructor for the Graph. Hidden pairs are skipped.
+func NewGraph(info api.Info) *Graph {
+	g := &Graph{
+		edges: make(map[string][]*Edge),
+		pairs: make(map[string][2]*Edge),
+	}
+
+	names := make([]string, 0, len(info.Pairs))
+	for pair := range info.Pairs {
+		names = append(names, pair)
+	}
+	sort.Strings(names)
+
+	for _, pair := range names {
+		if info.Hidden(pair) {
+			continue
+		}
+		base, quote := api.SplitPair(pair)
+		if quote == "" {
+			continue
+		}
+		sell := &Edge{Pair: pair, From: base, To: quote, Type: "sell", Fee: info.Fee(pair)}
+		buy := &Edge{Pair: pair, From: quote, To: base, Type: "buy", Fee: info.Fee(pair)}
+		g.edges[base] = append(g.edges[base], sell)
+		g.edges[quote] = append(g.edges[quote], buy)
+		g.pairs[pair] = [2]*Edge{sell, buy}
+	}
+
+	return g
+}
+
+// Pairs returns all pairs of the graph
+func (g *Graph) Pairs() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	pairs := make([]string, 0, len(g.pairs))
+	for pair := range g.pairs {
+		pairs = append(pairs, pair)
+	}
+	sort.Strings(pairs)
+
+	return pairs
+}
+
+// Edges returns copies of the edges leading from coin
+func (g *Graph) Edges(from string) []Edge {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	edges := make([]Edge, 0, len(g.edges[from]))
+	for _, e := range g.edges[from] {
+		edges = append(edges, *e)
+	}
+	return edges
+}
+
+// SetPrices sets the best bid and ask of the pair. Selling base goes at the bid,
+// buying base goes at the ask.
+func (g *Graph) SetPrices(pair string, bid, ask float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	edges, ok := g.pairs[pair]
+	if !ok {
+		return
+	}
+
+	sell, buy := edges[0], edges[1]
+	sell.Price, sell.Rate = bid, 0
+	if bid > 0 {
+		sell.Rate = bid * (1 - sell.Fee/100)
+	}
+	buy.Price, buy.Rate = ask, 0
+	if ask > 0 {
+		buy.Rate = 1 / ask * (1 - buy.Fee/100)
+	}
+}
+
+// UpdateTicker sets prices from the ticker (Buy is the bid, Sell is the ask)
+func (g *Graph) UpdateTicker(t api.Ticker) {
+	for pair, data := range t.PairData {
+		g.SetPrices(pair, data.Buy, data.Sell)
+	}
+}
+
+// UpdateDepth sets prices from the top of the order book
+func (g *Graph) UpdateDepth(d api.Depth) {
+	for pair, data := range d.PairData {
+		var bid, ask float64
+		if len(data.Bids) > 0 {
+			bid = data.Bids[0][0]
+		}
+		if len(data.Asks) > 0 {
+			ask = data.Asks[0][0]
+		}
+		g.SetPrices(pair, bid, ask)
+	}
+}
+
+// Refresh loads ticker prices of the pairs (all pairs of the graph if empty) in batches
+func (g *Graph) Refresh(market api.MarketData, pairs []string) error {
+	if len(pairs) == 0 {
+		pairs = g.Pairs()
+	}
+
+	tickers, err := api.Tickers(market, pairs)
+	if err != nil {
+		return err
+	}
+	g.UpdateTicker(api.Ticker{PairData: tickers})
+
+	return nil
+}
+
+// ShortestPath returns the path with the fewest hops between two coins
+func (g *Graph) ShortestPath(from, to string) (Path, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	from, to = strings.ToLower(from), strings.ToLower(to)
+	parents := map[string]*Edge{from: nil}
+	queue := []string{from}
+	for depth := 0; depth < MaxHops && len(queue) > 0; depth++ {
+		var next []string
+		for _, coin := range queue {
+			for _, e := range g.edges[coin] {
+				if _, ok := parents[e.To]; ok {
+					continue
+				}
+				parents[e.To] = e
+				next = append(next, e.To)
+			}
+		}
+		queue = next
+	}
+
+	if _, ok := parents[to]; !ok || from == to {
+		return nil, fmt.Errorf("convert: no path from %s to %s", from, to)
+	}
+
+	var path Path
+	for coin := to; coin != from; {
+		e := parents[coin]
+		path = append(Path{*e}, path...)
+		coin = e.From
+	}
+
+	return path, nil
+}
+
+// BestPath returns the path with the best rate after fees between two coins.
+// Only edges with known prices are used.
+func (g *Graph) BestPath(from, to string) (Path, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	from, to = strings.ToLower(from), strings.ToLower(to)
+	type state struct {
+		rate float64
+		path Path
+	}
+
+	// best[coin] is the best path reaching coin with at most the current number of hops
+	best := map[string]state{from: {rate: 1}}
+	for hop := 0; hop < MaxHops; hop++ {
+		next := make(map[string]state, len(best))
+		for coin, s := range best {
+			next[coin] = s
+		}
+		for coin, s := range best {
+			for _, e := range g.edges[coin] {
+				if e.Rate <= 0 || visits(s.path, e.To) || e.To == from {
+					continue
+				}
+				rate := s.rate * e.Rate
+				if cur, ok := next[e.To]; ok && cur.rate >= rate {
+					continue
+				}
+				path := make(Path, len(s.path), len(s.path)+1)
+				copy(path, s.path)
+				next[e.To] = state{rate: rate, path: append(path, *e)}
+			}
+		}
+		best = next
+	}
+	result := best[to]
+	if len(result.path) == 0 {
+		return nil, fmt.Errorf("convert: no priced path from %s to %s", from, to)
+	}
+
+	return result.path, nil
+}
+
+// Quote returns a copy of the path with current prices of the graph, e.g. to
+// execute a Report.Rollback path
+func (g *Graph) Quote(p Path) Path {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	quoted := make(Path, len(p))
+	for i, e := range p {
+		quoted[i] = e
+		for _, cur := range g.edges[e.From] {
+			if cur.Pair == e.Pair && cur.To == e.To {
+				quoted[i] = *cur
+			}
+		}
+	}
+	return quoted
+}
+
+// visits reports whether the path passes through coin
+func visits(p Path, coin string) bool {
+	for _, e := range p {
+		if e.From == coin || e.To == coin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,386 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Drift is a response field that is not known to the response models
+type Drift struct {
+	Type  string // Go type the field was found in (example: TradeHistory)
+	Path  string // path of the field in the response (example: return.12345.fee)
+	Field string // JSON name of the field
+}
+
+// DriftHandler is called for every unknown field of a decoded response. It is called
+// on every response, so handlers that log should deduplicate by Type and Field.
+type DriftHandler func(Drift)
+
+// DecodeError is returned when a response value doesn't fit the model field
+type DecodeError struct {
+	Path  string       // path of the value in the response
+	Value interface{}  // decoded JSON value
+	Type  reflect.Type // Go type of the field
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("api: cannot decode %v at %s into %s", e.Value, e.Path, e.Type)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Unmarshal decodes a Yobit response leniently: numeric fields accept both numbers and
+// numeric strings, time.Time fields accept unix seconds and RFC 3339 strings, and empty
+// JSON arrays are accepted in place of objects.
+func Unmarshal(data []byte, v interface{}) error {
+	return decode(data, v, nil)
+}
+
+// decode is Unmarshal reporting unknown struct fields to drift
+func decode(data []byte, v interface{}, drift DriftHandler) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var raw interface{}
+	err := dec.Decode(&raw)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("api: decode into non-pointer %T", v)
+	}
+
+	root := rv.Elem().Type().Name()
+	if root == "" {
+		root = rv.Elem().Type().String()
+	}
+
+	d := decoder{drift: drift, root: root}
+	return d.value(rv.Elem(), raw, "")
+}
+
+type decoder struct {
+	drift DriftHandler
+	root  string
+}
+
+func (d *decoder) value(dst reflect.Value, src interface{}, path string) error {
+	if src == nil {
+		return nil
+	}
+	if dst.Type() == timeType {
+		return d.time(dst, src, path)
+	}
+
+	switch dst.Kind() {
+	case reflect.Ptr:
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return d.value(dst.Elem(), src, path)
+
+	case reflect.Interface:
+		if dst.NumMethod() != 0 {
+			return &DecodeError{Path: path, Value: src, Type: dst.Type()}
+		}
+		dst.Set(reflect.ValueOf(plain(src)))
+		return nil
+
+	case reflect.String:
+		switch s := src.(type) {
+		case string:
+			dst.SetString(s)
+		case json.Number:
+			dst.SetString(s.String())
+		default:
+			return &DecodeError{Path: path, Value: src, Type: dst.Type()}
+		}
+		return nil
+
+	case reflect.Bool:
+		switch s := src.(type) {
+		case bool:
+			dst.SetBool(s)
+		case json.Number:
+			dst.SetBool(s.String() != "0")
+		case string:
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return &DecodeError{Path: path, Value: src, Type: dst.Type()}
+			}
+			dst.SetBool(b)
+		default:
+			return &DecodeError{Path: path, Value: src, Type: dst.Type()}
+		}
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := number(src)
+		if !ok {
+			return &DecodeError{Path: path, Value: src, Type: dst.Type()}
+		}
+		i, err := strconv.ParseInt(n, 10, 64)
+		if err != nil {
+			f, ferr := strconv.ParseFloat(n, 64)
+			if ferr != nil || f != math.Trunc(f) {
+				return &DecodeError{Path: path, Value: src, Type: dst.Type()}
+			}
+			i = int64(f)
+		}
+		if dst.OverflowInt(i) {
+			return &DecodeError{Path: path, Value: src, Type: dst.Type()}
+		}
+		dst.SetInt(i)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := number(src)
+		if !ok {
+			return &DecodeError{Path: path, Value: src, Type: dst.Type()}
+		}
+		u, err := strconv.ParseUint(n, 10, 64)
+		if err != nil {
+			f, ferr := strconv.ParseFloat(n, 64)
+			if ferr != nil || f < 0 || f != math.Trunc(f) {
+				return &DecodeError{Path: path, Value: src, Type: dst.Type()}
+			}
+			u = uint64(f)
+		}
+		if dst.OverflowUint(u) {
+			return &DecodeError{Path: path, Value: src, Type: dst.Type()}
+		}
+		dst.SetUint(u)
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		n, ok := number(src)
+		if !ok {
+			return &DecodeError{Path: path, Value: src, Type: dst.Type()}
+		}
+		f, err := strconv.ParseFloat(n, 64)
+		if err != nil || dst.OverflowFloat(f) {
+			return &DecodeError{Path: path, Value: src, Type: dst.Type()}
+		}
+		dst.SetFloat(f)
+		return nil
+
+	case reflect.Slice:
+		items, ok := src.([]interface{})
+		if !ok {
+			return &DecodeError{Path: path, Value: src, Type: dst.Type()}
+		}
+		slice := reflect.MakeSlice(dst.Type(), len(items), len(items))
+		for i, item := range items {
+			err := d.value(slice.Index(i), item, join(path, strconv.Itoa(i)))
+			if err != nil {
+				return err
+			}
+		}
+		dst.Set(slice)
+		return nil
+
+	case reflect.Array:
+		items, ok := src.([]interface{})
+		if !ok || len(items) > dst.Len() {
+			return &DecodeError{Path: path, Value: src, Type: dst.Type()}
+		}
+		for i, item := range items {
+			err := d.value(dst.Index(i), item, join(path, strconv.Itoa(i)))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		object, ok := d.object(src)
+		if !ok {
+			return &DecodeError{Path: path, Value: src, Type: dst.Type()}
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMap(dst.Type()))
+		}
+		for k, item := range object {
+			key := reflect.New(dst.Type().Key()).Elem()
+			err := d.value(key, k, join(path, k))
+			if err != nil {
+				return err
+			}
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			err = d.value(elem, item, join(path, k))
+			if err != nil {
+				return err
+			}
+			dst.SetMapIndex(key, elem)
+		}
+		return nil
+
+	case reflect.Struct:
+		object, ok := d.object(src)
+		if !ok {
+			return &DecodeError{Path: path, Value: src, Type: dst.Type()}
+		}
+		for k, item := range object {
+			field, ok := fieldByName(dst, k)
+			if !ok {
+				if d.drift != nil {
+					d.drift(Drift{Type: d.root, Path: join(path, k), Field: k})
+				}
+				continue
+			}
+			err := d.value(field, item, join(path, k))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return &DecodeError{Path: path, Value: src, Type: dst.Type()}
+}
+
+// time sets unix seconds, also fractional ones, or RFC 3339 strings, zero stays the
+// zero time
+func (d *decoder) time(dst reflect.Value, src interface{}, path string) error {
+	if s, ok := src.(string); ok {
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			dst.Set(reflect.ValueOf(t))
+			return nil
+		}
+	}
+
+	n, ok := number(src)
+	if !ok {
+		return &DecodeError{Path: path, Value: src, Type: dst.Type()}
+	}
+	var nsec int64
+	sec, err := strconv.ParseInt(n, 10, 64)
+	if err != nil {
+		f, ferr := strconv.ParseFloat(n, 64)
+		if ferr != nil || math.Abs(f) > math.MaxInt64 {
+			return &DecodeError{Path: path, Value: src, Type: dst.Type()}
+		}
+		whole := math.Floor(f)
+		sec, nsec = int64(whole), int64(math.Round((f-whole)*1e9))
+	}
+	if sec == 0 && nsec == 0 {
+		dst.Set(reflect.ValueOf(time.Time{}))
+		return nil
+	}
+	dst.Set(reflect.ValueOf(time.Unix(sec, nsec)))
+	return nil
+}
+
+// object returns JSON objects, the API sends empty arrays for empty objects
+func (d *decoder) object(src interface{}) (map[string]interface{}, bool) {
+	switch s := src.(type) {
+	case map[string]interface{}:
+		return s, true
+	case []interface{}:
+		return nil, len(s) == 0
+	}
+	return nil, false
+}
+
+// fieldByName finds the struct field by its JSON name like encoding/json does,
+// including fields of embedded structs
+func fieldByName(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	fold := -1
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			if field, ok := fieldByName(v.Field(i), name); ok {
+				return field, true
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if tag == "" {
+			tag = f.Name
+		}
+		if tag == name {
+			return v.Field(i), true
+		}
+		if fold < 0 && strings.EqualFold(tag, name) {
+			fold = i
+		}
+	}
+	if fold >= 0 {
+		return v.Field(fold), true
+	}
+	return reflect.Value{}, false
+}
+
+// number returns the text of numbers and numeric strings, empty strings are zero
+func number(src interface{}) (string, bool) {
+	switch s := src.(type) {
+	case json.Number:
+		return s.String(), true
+	case string:
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return "0", true
+		}
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return "", false
+		}
+		return s, true
+	}
+	return "", false
+}
+
+// plain converts json.Number to float64 as encoding/json does for interface{} values
+func plain(src interface{}) interface{} {
+	switch s := src.(type) {
+	case json.Number:
+		f, err := s.Float64()
+		if err != nil {
+			return s.String()
+		}
+		return f
+	case map[string]interface{}:
+		for k, v := range s {
+			s[k] = plain(v)
+		}
+	case []interface{}:
+		for i, v := range s {
+			s[i] = plain(v)
+		}
+	}
+	return src
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// Float returns the number of an untyped response value (example: Info pair fields),
+// accepting both numbers and numeric strings. Other values are 0.
+func Float(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case string:
+		f, _ := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f
+	}
+	return 0
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		v    interface{} // pointer to a new value of the decoded type
+		want interface{}
+	}{
+		{
+			"numbers as strings",
+			`{"success":1,"return":{"funds":{"btc":"0.5","ltc":2},"transaction_count":"0","server_time":"1700000000"}}`,
+			&GetInfo{},
+			&GetInfo{Success: 1, Return: InfoReturn{
+				Funds:      map[string]float64{"btc": 0.5, "ltc": 2},
+				ServerTime: time.Unix(1700000000, 0),
+			}},
+		},
+		{
+			"uint64 ids",
+			`{"success":1,"return":{"received":0,"remains":1,"order_id":18446744073709551615,"funds":{}}}`,
+			&Trade{},
+			&Trade{Success: 1, Return: TradeReturn{Remains: 1, OrderID: 18446744073709551615, Funds: map[string]float64{}}},
+		},
+		{
+			"uint64 ids as strings",
+			`{"success":1,"return":{"coupon":"YOBIT","transID":"9007199254740993","funds":[]}}`,
+			&CreateYobicode{},
+			&CreateYobicode{Success: 1, Return: CYReturn{Coupon: "YOBIT", TransID: 9007199254740993, Funds: map[string]float64{}}},
+		},
+		{
+			"trade timestamps",
+			`[{"type":"ask","price":"0.01","amount":1,"tid":"42","timestamp":1700000000},{"type":"bid","price":0.02,"amount":2,"tid":43,"timestamp":0}]`,
+			&[]TradeData{},
+			&[]TradeData{
+				{Type: "ask", Price: 0.01, Amount: 1, Tid: 42, Timestamp: time.Unix(1700000000, 0)},
+				{Type: "bid", Price: 0.02, Amount: 2, Tid: 43},
+			},
+		},
+		{
+			"empty array for an object",
+			`{"success":1,"return":[]}`,
+			&ActiveOrders{},
+			&ActiveOrders{Success: 1, Return: map[uint64]OrderData{}},
+		},
+		{
+			"orders",
+			`{"success":1,"return":{"100025362":{"pair":"ltc_btc","type":"sell","start_amount":"13.345","amount":"12.345",` +
+				`"rate":"485","timestamp_created":"1418654530","status":"0"}}}`,
+			&OrderInfo{},
+			&OrderInfo{Success: 1, Return: map[uint64]OrderData{100025362: {Pair: "ltc_btc", Type: "sell",
+				StartAmount: 13.345, Amount: 12.345, Rate: 485, TimestampCreated: time.Unix(1418654530, 0)}}},
+		},
+		{
+			"fractional timestamps",
+			`[{"timestamp":"1570000000.5"},{"timestamp":1570000000.25},{"timestamp":"0.0"}]`,
+			&[]TradeData{},
+			&[]TradeData{
+				{Timestamp: time.Unix(1570000000, 5e8)},
+				{Timestamp: time.Unix(1570000000, 25e7)},
+				{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Unmarshal([]byte(tt.body), tt.v); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Fatalf("got %+v, want %+v", tt.v, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		v    interface{}
+	}{
+		{"negative id", `{"order_id":-1}`, &TradeReturn{}},
+		{"fractional id", `{"order_id":1.5}`, &TradeReturn{}},
+		{"text for a number", `{"received":"abc"}`, &TradeReturn{}},
+		{"text for a time", `{"timestamp":"yesterday"}`, &TradeData{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var de *DecodeError
+			if err := Unmarshal([]byte(tt.body), tt.v); !errors.As(err, &de) {
+				t.Fatalf("err = %v, want *DecodeError", err)
+			}
+		})
+	}
+}
+
+func TestDecodeDrift(t *testing.T) {
+	var drifts []Drift
+	var info GetInfo
+	err := decode([]byte(`{"success":1,"return":{"funds":{},"new_field":1}}`), &info, func(d Drift) {
+		drifts = append(drifts, d)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Drift{{Type: "GetInfo", Path: "return.new_field", Field: "new_field"}}
+	if !reflect.DeepEqual(drifts, want) {
+		t.Fatalf("drifts = %v, want %v", drifts, want)
+	}
+}
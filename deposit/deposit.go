@@ -0,0 +1,219 @@
+// Package deposit caches deposit addresses and detects credited deposits by
+// polling the processed amounts of GetDepositAddress and, optionally, the
+// balances of GetInfo.
+package deposit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// Sources of deposit events
+const (
+	SourceProcessed = "processed_amount" // processed amount of the deposit address grew
+	SourceFunds     = "funds"            // account balance grew, may also be caused by trades
+)
+
+// Trader provides deposit addresses (e.g. *api.TradeAPI)
+type Trader interface {
+	GetDepositAddress(t *api.GetDepositAddressSettings) (api.GetDepositAddress, error)
+}
+
+// Balances provides account balances (e.g. *api.TradeAPI)
+type Balances interface {
+	GetInfo() (api.GetInfo, error)
+}
+
+// Event is a credited deposit
+type Event struct {
+	Coin    string    // coin (example: btc)
+	Address string    // deposit address of the coin
+	Source  string    // processed_amount or funds
+	Amount  float64   // credited amount since the previous poll
+	Total   float64   // processed amount of the address or balance after the deposit
+	Time    time.Time // time of the poll
+}
+
+// Monitor polls deposit addresses of the coins
+type Monitor struct {
+	// Errors is called with the errors of the polls made by Run, if set
+	Errors func(error)
+	// Balances are polled for funds events too, if set (off on default). Balances
+	// also grow with filled orders and redeemed Yobicodes, so funds events can be
+	// false positives and are best confirmed with the processed amount.
+	Balances Balances
+
+	trader Trader
+	coins  []string
+
+	mu        sync.Mutex
+	addresses map[string]string
+	processed map[string]float64 // processed amount by coin
+	polled    map[string]string  // address the processed amount was polled for
+	funds     map[string]float64 // balance including orders by coin
+}
+
+// NewMonitor is a constructor for the Monitor of the coins (example: btc, ltc)
+func NewMonitor(trader Trader, coins []string) *Monitor {
+	m := &Monitor{
+		trader:    trader,
+		addresses: make(map[string]string),
+		processed: make(map[string]float64),
+		polled:    make(map[string]string),
+		funds:     make(map[string]float64),
+	}
+	for _, coin := range coins {
+		m.coins = append(m.coins, strings.ToLower(coin))
+	}
+
+	return m
+}
+
+// Address returns the cached deposit address of the coin, loading it on first use
+func (m *Monitor) Address(coin string) (string, error) {
+	coin = strings.ToLower(coin)
+
+	m.mu.Lock()
+	address, ok := m.addresses[coin]
+	m.mu.Unlock()
+	if ok {
+		return address, nil
+	}
+
+	gda, err := m.load(coin, false)
+	if err != nil {
+		return "", err
+	}
+
+	return gda.Address, nil
+}
+
+// NewAddress requests a new deposit address of the coin and caches it
+func (m *Monitor) NewAddress(coin string) (string, error) {
+	gda, err := m.load(strings.ToLower(coin), true)
+	if err != nil {
+		return "", err
+	}
+
+	return gda.Address, nil
+}
+
+// Run polls every interval and sends events to out until the context is done.
+// Failed polls are reported to Errors and retried on the next tick.
+func (m *Monitor) Run(ctx context.Context, interval time.Duration, out chan<- Event) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		events, err := m.Poll()
+		if err != nil && m.Errors != nil {
+			m.Errors(err)
+		}
+		for _, e := range events {
+			select {
+			case out <- e:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll loads processed amounts, and balances if set, once and returns deposits
+// credited since the previous poll. The first poll of an address only records its
+// processed amount, the first poll of the balances only records them. Coins that
+// failed to load are skipped until the next poll, the first error is returned with
+// the events of the other coins.
+func (m *Monitor) Poll() ([]Event, error) {
+	now := time.Now()
+
+	var events []Event
+	var first error
+	for _, coin := range m.coins {
+		gda, err := m.load(coin, false)
+		if err != nil {
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+
+		m.mu.Lock()
+		// the processed amount belongs to the address, a new address starts from zero
+		address, ok := m.polled[coin]
+		if delta := gda.ProcessedAmount - m.processed[coin]; ok && address == gda.Address && delta > 0 {
+			events = append(events, Event{Coin: coin, Address: gda.Address, Source: SourceProcessed, Amount: delta, Total: gda.ProcessedAmount, Time: now})
+		}
+		m.processed[coin] = gda.ProcessedAmount
+		m.polled[coin] = gda.Address
+		m.mu.Unlock()
+	}
+
+	if m.Balances != nil {
+		fundsEvents, err := m.pollFunds(now)
+		if err != nil && first == nil {
+			first = err
+		}
+		events = append(events, fundsEvents...)
+	}
+
+	return events, first
+}
+
+// pollFunds loads the balances and returns the coins whose balance grew
+func (m *Monitor) pollFunds(now time.Time) ([]Event, error) {
+	balance, err := m.Balances.GetInfo()
+	if err != nil {
+		return nil, err
+	}
+	if balance.Success == 0 {
+		return nil, fmt.Errorf("deposit: getInfo: %s", balance.Error)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var events []Event
+	for _, coin := range m.coins {
+		funds := balance.Return.FundsInclOrders[coin]
+		if prev, ok := m.funds[coin]; ok && funds > prev {
+			events = append(events, Event{Coin: coin, Address: m.addresses[coin], Source: SourceFunds, Amount: funds - prev, Total: funds, Time: now})
+		}
+		m.funds[coin] = funds
+	}
+
+	return events, nil
+}
+
+// load calls GetDepositAddress and caches the address
+func (m *Monitor) load(coin string, needNew bool) (api.GDAReturn, error) {
+	settings := &api.GetDepositAddressSettings{CoinName: coin}
+	if needNew {
+		settings.NeedNew = 1
+	}
+
+	resp, err := m.trader.GetDepositAddress(settings)
+	if err != nil {
+		return api.GDAReturn{}, err
+	}
+	if resp.Success == 0 {
+		return api.GDAReturn{}, fmt.Errorf("deposit: %s: %s", coin, resp.Error)
+	}
+
+	m.mu.Lock()
+	m.addresses[coin] = resp.Return.Address
+	m.mu.Unlock()
+
+	return resp.Return, nil
+}
@@ -0,0 +1,130 @@
+package deposit
+
+import (
+	"errors"
+	"testing"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// fakeTrader returns the next processed amount of each coin on every poll,
+// a negative amount is a transport error
+type fakeTrader struct {
+	processed map[string][]float64
+	address   map[string]string
+}
+
+func (f *fakeTrader) GetDepositAddress(t *api.GetDepositAddressSettings) (api.GetDepositAddress, error) {
+	amounts := f.processed[t.CoinName]
+	amount := amounts[0]
+	if len(amounts) > 1 {
+		f.processed[t.CoinName] = amounts[1:]
+	}
+	if amount < 0 {
+		return api.GetDepositAddress{}, errors.New("timeout")
+	}
+
+	gda := api.GetDepositAddress{Success: 1}
+	gda.Return.Address = f.address[t.CoinName]
+	gda.Return.ProcessedAmount = amount
+	return gda, nil
+}
+
+func TestPoll(t *testing.T) {
+	trader := &fakeTrader{
+		processed: map[string][]float64{
+			"btc": {1, 1.5, -1, 2, 2},
+			"ltc": {0, 0, 3, 3, 3},
+		},
+		address: map[string]string{"btc": "a", "ltc": "b"},
+	}
+	m := NewMonitor(trader, []string{"BTC", "LTC"})
+
+	tests := []struct {
+		events map[string]float64
+		err    bool
+	}{
+		{nil, false},
+		{map[string]float64{"btc": 0.5}, false},
+		{map[string]float64{"ltc": 3}, true},
+		{map[string]float64{"btc": 0.5}, false},
+		{nil, false},
+	}
+
+	for i, tt := range tests {
+		events, err := m.Poll()
+		if (err != nil) != tt.err {
+			t.Fatalf("poll %d: err = %v, want error %v", i, err, tt.err)
+		}
+		if len(events) != len(tt.events) {
+			t.Fatalf("poll %d: events = %v, want %v", i, events, tt.events)
+		}
+		for _, e := range events {
+			if e.Amount != tt.events[e.Coin] {
+				t.Fatalf("poll %d: %s deposit = %v, want %v", i, e.Coin, e.Amount, tt.events[e.Coin])
+			}
+		}
+	}
+
+	// a new address starts from its own processed amount
+	trader.address["btc"] = "c"
+	trader.processed["btc"] = []float64{0.1}
+	if events, _ := m.Poll(); len(events) != 0 {
+		t.Fatalf("events after a new address = %v, want none", events)
+	}
+}
+
+// fakeBalances returns the next balances on every poll, nil is a transport error
+type fakeBalances struct {
+	funds []map[string]float64
+}
+
+func (f *fakeBalances) GetInfo() (api.GetInfo, error) {
+	funds := f.funds[0]
+	f.funds = f.funds[1:]
+	if funds == nil {
+		return api.GetInfo{}, errors.New("timeout")
+	}
+	return api.GetInfo{Success: 1, Return: api.InfoReturn{FundsInclOrders: funds}}, nil
+}
+
+func TestPollFunds(t *testing.T) {
+	trader := &fakeTrader{
+		processed: map[string][]float64{"btc": {1, 1, 1, 1.5}},
+		address:   map[string]string{"btc": "a"},
+	}
+	balances := &fakeBalances{funds: []map[string]float64{
+		{"btc": 2, "usd": 10},
+		{"btc": 2.5, "usd": 20}, // bought with usd, not a deposit but reported
+		nil,
+		{"btc": 3},
+	}}
+
+	m := NewMonitor(trader, []string{"btc"})
+	m.Balances = balances
+
+	tests := []struct {
+		sources map[string]float64
+		err     bool
+	}{
+		{nil, false},
+		{map[string]float64{SourceFunds: 0.5}, false},
+		{nil, true},
+		{map[string]float64{SourceProcessed: 0.5, SourceFunds: 0.5}, false},
+	}
+
+	for i, tt := range tests {
+		events, err := m.Poll()
+		if (err != nil) != tt.err {
+			t.Fatalf("poll %d: err = %v, want error %v", i, err, tt.err)
+		}
+		if len(events) != len(tt.sources) {
+			t.Fatalf("poll %d: events = %v, want %v", i, events, tt.sources)
+		}
+		for _, e := range events {
+			if e.Amount != tt.sources[e.Source] || e.Address != "a" {
+				t.Fatalf("poll %d: %s event = %+v, want %v", i, e.Source, e, tt.sources[e.Source])
+			}
+		}
+	}
+}
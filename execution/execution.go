@@ -0,0 +1,378 @@
+// Package execution implements TWAP and iceberg execution algorithms that slice
+// a parent order into child orders placed through the Yobit Trade API.
+package execution
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// State is a state of the parent order
+type State string
+
+const (
+	Running   State = "running"
+	Paused    State = "paused"
+	Cancelled State = "cancelled"
+	Done      State = "done"
+	Expired   State = "expired" // the deadline was reached before the parent order was filled
+	Failed    State = "failed"
+)
+
+// Settings describes the parent order
+type Settings struct {
+	Pair             string        `json:"pair"`              // pair (example: ltc_btc)
+	Type             string        `json:"type"`              // transaction type (example: buy or sell)
+	Rate             float64       `json:"rate"`              // limit price of every child order
+	Amount           float64       `json:"amount"`            // total amount of the parent order
+	Interval         time.Duration `json:"interval"`          // how often child orders are checked and placed
+	MaxParticipation float64       `json:"max_participation"` // max share of observed market volume per interval (0 - no cap)
+	MinAmount        float64       `json:"min_amount"`        // minimal child amount accepted by the pair
+}
+
+// TWAPSettings describes a TWAP parent order
+type TWAPSettings struct {
+	Settings
+	Duration time.Duration `json:"duration"` // time over which the parent order is spread
+}
+
+// IcebergSettings describes an iceberg parent order
+type IcebergSettings struct {
+	Settings
+	Visible float64 `json:"visible"` // amount shown on the book at a time
+}
+
+// Progress reports the execution state of the parent order
+type Progress struct {
+	State     State   // current state
+	Filled    float64 // amount filled so far
+	Working   float64 // amount resting on the book in the current child
+	Remaining float64 // amount not filled yet
+	Children  int     // number of child orders placed
+	Err       error   // last error, if any
+}
+
+// Execution runs a parent order
+type Execution struct {
+	settings Settings
+	trader   Trader
+	trades   TradesSource
+
+	// size returns the desired amount of the next child order
+	size func(e *Execution, now time.Time) float64
+	// replace reports whether a working child should be cancelled and placed again
+	replace bool
+	// deadline stops the execution when reached (zero - no deadline)
+	deadline time.Time
+
+	// mu guards the progress, network calls are made without it; only the Run
+	// goroutine changes filled, children and child
+	mu       sync.Mutex
+	state    State
+	filled   float64
+	children int
+	child    *child
+	lastSeen time.Time
+	wake     chan struct{}
+
+	// Notify is called after every change of progress, if set
+	Notify func(Progress)
+}
+
+// NewTWAP is a constructor for a TWAP execution. Child orders are placed every Interval
+// so that the parent order is spread evenly over Duration; unfilled children are
+// cancelled and their remainder carried over to the next slice.
+func NewTWAP(trader Trader, trades TradesSource, s TWAPSettings) (*Execution, error) {
+	if s.Duration < s.Interval {
+		return nil, errors.New("execution: duration is shorter than interval")
+	}
+
+	e, err := newExecution(trader, trades, s.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	e.deadline = start.Add(s.Duration)
+	e.replace = true
+	e.size = func(e *Execution, now time.Time) float64 {
+		slicesLeft := int((e.deadline.Sub(now) + e.settings.Interval - 1) / e.settings.Interval)
+		if slicesLeft < 1 {
+			slicesLeft = 1
+		}
+		return (e.settings.Amount - e.filled) / float64(slicesLeft)
+	}
+
+	return e, nil
+}
+
+// NewIceberg is a constructor for an iceberg execution. Only Visible amount rests on
+// the book at a time; the next clip is placed when the previous one is filled.
+func NewIceberg(trader Trader, trades TradesSource, s IcebergSettings) (*Execution, error) {
+	if s.Visible <= 0 {
+		return nil, errors.New("execution: visible amount hasn't been set")
+	}
+
+	e, err := newExecution(trader, trades, s.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	e.size = func(e *Execution, now time.Time) float64 {
+		return s.Visible
+	}
+
+	return e, nil
+}
+
+func newExecution(trader Trader, trades TradesSource, s Settings) (*Execution, error) {
+	if s.Pair == "" {
+		return nil, errors.New("execution: pair hasn't been set")
+	}
+	if s.Type != "buy" && s.Type != "sell" {
+		return nil, errors.New("execution: type must be buy or sell")
+	}
+	if s.Rate <= 0 || s.Amount <= 0 {
+		return nil, errors.New("execution: rate and amount must be positive")
+	}
+	if s.Interval <= 0 {
+		return nil, errors.New("execution: interval hasn't been set")
+	}
+	if s.MaxParticipation > 0 && trades == nil {
+		return nil, errors.New("execution: participation cap needs a trades source")
+	}
+
+	return &Execution{
+		settings: s,
+		trader:   trader,
+		trades:   trades,
+		state:    Running,
+		lastSeen: time.Now().Add(-s.Interval),
+		wake:     make(chan struct{}, 1),
+	}, nil
+}
+
+// Run executes the parent order until it is filled, cancelled, failed or the context is done
+func (e *Execution) Run(ctx context.Context) error {
+	ticker := time.NewTicker(e.settings.Interval)
+	defer ticker.Stop()
+
+	for {
+		err := e.step(time.Now())
+		e.notify(err)
+
+		e.mu.Lock()
+		state := e.state
+		e.mu.Unlock()
+		switch state {
+		case Done, Expired, Cancelled:
+			return nil
+		case Failed:
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			e.Cancel()
+			e.step(time.Now())
+			e.notify(nil)
+			return ctx.Err()
+		case <-ticker.C:
+		case <-e.wake:
+		}
+	}
+}
+
+// Pause cancels the working child and stops placing new ones until Resume
+func (e *Execution) Pause() {
+	e.setState(Paused)
+}
+
+// Resume continues a paused execution
+func (e *Execution) Resume() {
+	e.setState(Running)
+}
+
+// Cancel cancels the working child and stops the whole parent order
+func (e *Execution) Cancel() {
+	e.setState(Cancelled)
+}
+
+// Progress returns the current progress of the parent order
+func (e *Execution) Progress() Progress {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.progress(nil)
+}
+
+func (e *Execution) setState(state State) {
+	e.mu.Lock()
+	if e.state == Running || e.state == Paused {
+		e.state = state
+	}
+	e.mu.Unlock()
+
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (e *Execution) notify(err error) {
+	if e.Notify == nil {
+		return
+	}
+
+	e.mu.Lock()
+	p := e.progress(err)
+	e.mu.Unlock()
+
+	e.Notify(p)
+}
+
+func (e *Execution) progress(err error) Progress {
+	p := Progress{
+		State:     e.state,
+		Filled:    e.filled,
+		Remaining: e.settings.Amount - e.filled,
+		Children:  e.children,
+		Err:       err,
+	}
+	if e.child != nil {
+		p.Working = e.child.remains
+	}
+
+	return p
+}
+
+// step refreshes the working child and places the next one if needed
+func (e *Execution) step(now time.Time) error {
+	e.mu.Lock()
+	state := e.state
+	c := e.child
+	if c != nil {
+		// the copy is updated without the lock and stored by apply
+		copied := *c
+		c = &copied
+	}
+	e.mu.Unlock()
+
+	if state == Done || state == Expired || state == Failed {
+		return nil
+	}
+
+	if c != nil {
+		filled, done, err := c.refresh(e.trader)
+		if done {
+			c = nil
+		}
+		e.apply(c, filled)
+		if err != nil {
+			return err
+		}
+	}
+
+	finished := e.settings.Amount-e.filled < e.settings.MinAmount || e.settings.Amount-e.filled <= 0
+	expired := !e.deadline.IsZero() && !now.Before(e.deadline)
+	if c != nil && (state != Running || e.replace || finished || expired) {
+		filled, err := c.cancel(e.trader)
+		if err == nil {
+			c = nil
+		}
+		e.apply(c, filled)
+		if err != nil {
+			return err
+		}
+	}
+
+	if state != Running {
+		return nil
+	}
+	if finished || expired {
+		e.mu.Lock()
+		if e.state == Running {
+			e.state = Done
+			if !finished {
+				e.state = Expired
+			}
+		}
+		e.mu.Unlock()
+		return nil
+	}
+	if c != nil {
+		return nil
+	}
+
+	amount := e.size(e, now)
+	if e.settings.MaxParticipation > 0 {
+		volume, err := e.marketVolume()
+		if err != nil {
+			return err
+		}
+		if limit := volume * e.settings.MaxParticipation; amount > limit {
+			amount = limit
+		}
+	}
+	if remaining := e.settings.Amount - e.filled; amount > remaining {
+		amount = remaining
+	}
+	if amount < e.settings.MinAmount || amount <= 0 {
+		return nil
+	}
+
+	c, filled, err := place(e.trader, &api.TradeSettings{
+		Pair:   e.settings.Pair,
+		Type:   e.settings.Type,
+		Rate:   e.settings.Rate,
+		Amount: amount,
+	})
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err != nil {
+		e.state = Failed
+		return err
+	}
+	e.children++
+	e.filled += filled
+	e.child = c
+
+	return nil
+}
+
+// apply stores the refreshed working child (nil - no child) and its newly filled amount
+func (e *Execution) apply(c *child, filled float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.child = c
+	e.filled += filled
+}
+
+// marketVolume returns the market volume traded since the previous call
+func (e *Execution) marketVolume() (float64, error) {
+	trades, err := e.trades.Trades(&api.TradesSettings{Pair: e.settings.Pair})
+	if err != nil {
+		return 0, err
+	}
+
+	var volume float64
+	last := e.lastSeen
+	for _, t := range trades.PairData[e.settings.Pair] {
+		if t.Timestamp.After(e.lastSeen) {
+			volume += t.Amount
+			if t.Timestamp.After(last) {
+				last = t.Timestamp
+			}
+		}
+	}
+	e.lastSeen = last
+
+	return volume, nil
+}
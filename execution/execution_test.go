@@ -0,0 +1,135 @@
+package execution
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// fakeTrader rests every order on the book, filling fill of it per OrderInfo
+type fakeTrader struct {
+	fill float64
+
+	mu     sync.Mutex
+	orders map[uint64]float64 // remaining amount by order ID
+	status map[uint64]int
+	block  chan struct{} // OrderInfo waits on it, if set
+}
+
+func newFakeTrader(fill float64) *fakeTrader {
+	return &fakeTrader{fill: fill, orders: make(map[uint64]float64), status: make(map[uint64]int)}
+}
+
+func (f *fakeTrader) Trade(t *api.TradeSettings) (api.Trade, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := uint64(len(f.orders) + 1)
+	f.orders[id] = t.Amount
+	return api.Trade{Success: 1, Return: api.TradeReturn{OrderID: id, Remains: t.Amount}}, nil
+}
+
+func (f *fakeTrader) OrderInfo(t *api.OrderInfoSettings) (api.OrderInfo, error) {
+	if f.block != nil {
+		<-f.block
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.status[t.OrderID] == statusActive {
+		f.orders[t.OrderID] -= f.fill
+		if f.orders[t.OrderID] <= 0 {
+			f.orders[t.OrderID] = 0
+			f.status[t.OrderID] = statusFilled
+		}
+	}
+	return api.OrderInfo{Success: 1, Return: map[uint64]api.OrderData{
+		t.OrderID: {Amount: f.orders[t.OrderID], Status: f.status[t.OrderID]},
+	}}, nil
+}
+
+func (f *fakeTrader) CancelOrder(t *api.CancelOrderSettings) (api.CancelOrder, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.status[t.OrderID] = statusCancelledPartFilled
+	return api.CancelOrder{Success: 1}, nil
+}
+
+func TestTWAPDeadline(t *testing.T) {
+	tests := []struct {
+		name   string
+		fill   float64
+		state  State
+		filled float64
+	}{
+		{"filled", 10, Done, 10},
+		{"unfilled at the deadline", 0, Expired, 0},
+		{"partially filled at the deadline", 1, Expired, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewTWAP(newFakeTrader(tt.fill), nil, TWAPSettings{
+				Settings: Settings{Pair: "ltc_btc", Type: "buy", Rate: 1, Amount: 10, Interval: time.Minute},
+				Duration: time.Minute,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			start := time.Now()
+			if err := e.step(start); err != nil {
+				t.Fatal(err)
+			}
+			if err := e.step(start.Add(time.Minute)); err != nil {
+				t.Fatal(err)
+			}
+
+			p := e.Progress()
+			if p.State != tt.state || p.Filled != tt.filled {
+				t.Fatalf("progress = %s %v, want %s %v", p.State, p.Filled, tt.state, tt.filled)
+			}
+		})
+	}
+}
+
+func TestProgressDuringStep(t *testing.T) {
+	trader := newFakeTrader(1)
+	e, err := NewIceberg(trader, nil, IcebergSettings{
+		Settings: Settings{Pair: "ltc_btc", Type: "sell", Rate: 1, Amount: 10, Interval: time.Minute},
+		Visible:  2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.step(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	trader.block = make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- e.step(time.Now())
+	}()
+
+	// OrderInfo is blocked, the progress and Pause must not wait for it
+	if p := e.Progress(); p.Working != 2 || p.Children != 1 {
+		t.Fatalf("progress = %+v, want the first child working", p)
+	}
+	e.Pause()
+	close(trader.block)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.step(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if p := e.Progress(); p.State != Paused || p.Filled != 1 || p.Working != 0 {
+		t.Fatalf("progress = %+v, want paused with the child cancelled", p)
+	}
+}
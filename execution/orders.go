@@ -0,0 +1,104 @@
+package execution
+
+import (
+	"fmt"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// order statuses returned by OrderInfo
+const (
+	statusActive              = 0
+	statusFilled              = 1
+	statusCancelled           = 2
+	statusCancelledPartFilled = 3
+)
+
+// Trader places and tracks child orders (e.g. *api.TradeAPI)
+type Trader interface {
+	Trade(t *api.TradeSettings) (api.Trade, error)
+	OrderInfo(t *api.OrderInfoSettings) (api.OrderInfo, error)
+	CancelOrder(t *api.CancelOrderSettings) (api.CancelOrder, error)
+}
+
+// TradesSource provides market trades for participation caps (e.g. *api.PublicAPI)
+type TradesSource interface {
+	Trades(t *api.TradesSettings) (api.Trades, error)
+}
+
+// child is a working child order
+type child struct {
+	id      uint64  // order ID
+	amount  float64 // amount the child was placed with
+	filled  float64 // amount already counted as filled
+	remains float64 // amount still resting on the book
+}
+
+// place sends a child order and returns it, or nil if it was filled at once
+func place(trader Trader, s *api.TradeSettings) (*child, float64, error) {
+	trade, err := trader.Trade(s)
+	if err != nil {
+		return nil, 0, err
+	}
+	if trade.Success == 0 {
+		return nil, 0, fmt.Errorf("execution: trade rejected: %s", trade.Error)
+	}
+
+	if trade.Return.OrderID == 0 || trade.Return.Remains == 0 {
+		return nil, s.Amount, nil
+	}
+
+	c := &child{
+		id:      trade.Return.OrderID,
+		amount:  s.Amount,
+		filled:  s.Amount - trade.Return.Remains,
+		remains: trade.Return.Remains,
+	}
+
+	return c, c.filled, nil
+}
+
+// refresh loads the child state and returns the newly filled amount and whether the child is done
+func (c *child) refresh(trader Trader) (float64, bool, error) {
+	info, err := trader.OrderInfo(&api.OrderInfoSettings{OrderID: c.id})
+	if err != nil {
+		return 0, false, err
+	}
+	if info.Success == 0 {
+		return 0, false, fmt.Errorf("execution: order info %d: %s", c.id, info.Error)
+	}
+
+	data, ok := info.Return[c.id]
+	if !ok {
+		return 0, false, fmt.Errorf("execution: order %d not found", c.id)
+	}
+
+	remains := data.Amount
+
+	filled := c.amount - remains - c.filled
+	if filled < 0 {
+		filled = 0
+	}
+	c.filled += filled
+	c.remains = remains
+
+	return filled, data.Status != statusActive, nil
+}
+
+// cancel cancels the child and returns the amount filled since the last refresh
+func (c *child) cancel(trader Trader) (float64, error) {
+	resp, err := trader.CancelOrder(&api.CancelOrderSettings{OrderID: c.id})
+	if err != nil {
+		return 0, err
+	}
+
+	filled, done, err := c.refresh(trader)
+	if err != nil {
+		return filled, err
+	}
+	if !done && resp.Success == 0 {
+		return filled, fmt.Errorf("execution: cancel order %d: %s", c.id, resp.Error)
+	}
+
+	return filled, nil
+}
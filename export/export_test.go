@@ -0,0 +1,147 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	api "github.com/vladivolo/yobit-api"
+	"github.com/vladivolo/yobit-api/historysync"
+	"github.com/vladivolo/yobit-api/yobicode"
+)
+
+func TestJournalInterceptor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j, err := OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := []struct {
+		method string
+		params url.Values
+		body   string
+	}{
+		{"WithdrawCoinsToAddress", url.Values{"coinName": {"BTC"}, "amount": {"0.5"}, "address": {"1abc"}},
+			`{"success":1,"return":{"server_time":1}}`},
+		{"WithdrawCoinsToAddress", url.Values{"coinName": {"BTC"}, "amount": {"9"}, "address": {"1abc"}},
+			`{"success":0,"error":"Insufficient funds"}`},
+		{"CreateYobicode", url.Values{"coinName": {"ltc"}, "amount": {"2"}},
+			`{"success":1,"return":{"coupon":"YOBITSECRET","transID":1,"funds":{"ltc":1}}}`},
+		{"RedeemYobicode", url.Values{"coupon": {"YOBITSECRET"}},
+			`{"success":1,"return":{"couponAmount":"3","couponCurrency":"DOGE","transID":1}}`},
+		{"Trade", url.Values{"pair": {"ltc_btc"}}, `{"success":1,"return":{}}`},
+	}
+	intercept := j.Interceptor()
+	for _, c := range calls {
+		req := &api.Request{RequestInfo: api.RequestInfo{Method: c.method}, Params: c.params, Signed: true}
+		_, err := intercept(context.Background(), req, func(context.Context, *api.Request) (*api.Response, error) {
+			return &api.Response{Status: 200, Body: []byte(c.body)}, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the records survive reopening
+	j, err = OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	withdrawals := j.Withdrawals()
+	if len(withdrawals) != 1 || withdrawals[0].CoinName != "btc" || withdrawals[0].Amount != 0.5 || withdrawals[0].Address != "1abc" {
+		t.Fatalf("withdrawals = %+v, want the successful one", withdrawals)
+	}
+	ops := j.Yobicodes()
+	want := []YobicodeOp{{Currency: "ltc", Amount: 2}, {Redeem: true, Currency: "doge", Amount: 3}}
+	if len(ops) != len(want) {
+		t.Fatalf("yobicodes = %+v, want %+v", ops, want)
+	}
+	for i, op := range ops {
+		op.Time = time.Time{}
+		if op != want[i] {
+			t.Fatalf("yobicode %d = %+v, want %+v", i, op, want[i])
+		}
+	}
+}
+
+func TestYobicodeOps(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []yobicode.Entry{
+		{ID: "a", Currency: "btc", Amount: 1, Label: "gift", Created: created},
+		{ID: "b", Currency: "ltc", Amount: 2, Created: created, Redeemed: true, RedeemedAt: created.Add(time.Hour)},
+		{ID: "c", Currency: "doge", Amount: 3, Redeemed: true, RedeemedAt: created.Add(time.Hour)},
+	}
+
+	tests := []YobicodeOp{
+		{Time: created, Currency: "btc", Amount: 1, Label: "gift"},
+		{Time: created, Currency: "ltc", Amount: 2},
+		{Time: created.Add(time.Hour), Redeem: true, Currency: "ltc", Amount: 2},
+		{Time: created.Add(time.Hour), Redeem: true, Currency: "doge", Amount: 3},
+	}
+	ops := YobicodeOps(entries)
+	if len(ops) != len(tests) {
+		t.Fatalf("ops = %+v, want %+v", ops, tests)
+	}
+	for i, want := range tests {
+		if ops[i] != want {
+			t.Errorf("op %d = %+v, want %+v", i, ops[i], want)
+		}
+	}
+}
+
+func TestPriceMaxAge(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := NewPriceHistory()
+	h.Record(Snapshot{Time: at, Pair: "btc_usd", Price: 100})
+
+	tests := []struct {
+		name string
+		t    time.Time
+		ok   bool
+	}{
+		{"before the snapshot", at.Add(-time.Second), false},
+		{"at the snapshot", at, true},
+		{"within max age", at.Add(DefaultMaxAge), true},
+		{"stale", at.Add(DefaultMaxAge + time.Second), false},
+	}
+	for _, tt := range tests {
+		if _, ok := h.Price("btc_usd", tt.t); ok != tt.ok {
+			t.Errorf("%s: ok = %v, want %v", tt.name, ok, tt.ok)
+		}
+	}
+}
+
+func TestKoinlyTxHash(t *testing.T) {
+	l := NewLedger(api.Info{}, nil, "")
+	l.AddTrades([]historysync.Record{{ID: 42, THReturn: api.THReturn{Pair: "ltc_btc", Type: "buy", Amount: 1, Rate: 1}}})
+	w := Withdrawal{TxHash: "0xabc"}
+	w.CoinName, w.Amount, w.Address = "eth", 1, "0xdef"
+	l.AddWithdrawals([]Withdrawal{w})
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, l.Rows(), Koinly); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	column := len(records[0]) - 1
+	if records[0][column] != "TxHash" {
+		t.Fatalf("last column = %q, want TxHash", records[0][column])
+	}
+	hashes := map[string]bool{records[1][column]: true, records[2][column]: true}
+	if !hashes[""] || !hashes["0xabc"] {
+		t.Fatalf("tx hashes = %v, want an empty one for the trade and 0xabc", hashes)
+	}
+}
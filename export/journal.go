@@ -0,0 +1,202 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// record is a line of the journal file
+type record struct {
+	Withdrawal *Withdrawal `json:"withdrawal,omitempty"`
+	Yobicode   *YobicodeOp `json:"yobicode,omitempty"`
+}
+
+// Journal records withdrawals and Yobicode operations in a JSON lines file, as the
+// API has no history of them. Journal.Interceptor records the successful calls of
+// the Trade API.
+type Journal struct {
+	// Errors receives errors of writing records made by the interceptor, as they
+	// can't fail the recorded call. The first one is also returned by Close.
+	Errors func(error)
+
+	mu          sync.Mutex
+	file        *os.File
+	err         error
+	withdrawals []Withdrawal
+	yobicodes   []YobicodeOp
+}
+
+// OpenJournal opens the journal file for appending, creating it if needed
+func OpenJournal(path string) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &Journal{file: f}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		var r record
+		err = json.Unmarshal(scanner.Bytes(), &r)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("export: %s:%d: %v", path, line, err)
+		}
+		j.add(r)
+	}
+	if err := scanner.Err(); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	err := j.file.Close()
+	if j.err != nil {
+		return j.err
+	}
+	return err
+}
+
+// Withdrawals returns the recorded withdrawals
+func (j *Journal) Withdrawals() []Withdrawal {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return append([]Withdrawal(nil), j.withdrawals...)
+}
+
+// Yobicodes returns the recorded Yobicode operations
+func (j *Journal) Yobicodes() []YobicodeOp {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return append([]YobicodeOp(nil), j.yobicodes...)
+}
+
+// RecordWithdrawal appends the withdrawal
+func (j *Journal) RecordWithdrawal(w Withdrawal) error {
+	return j.write(record{Withdrawal: &w})
+}
+
+// RecordYobicode appends the Yobicode operation
+func (j *Journal) RecordYobicode(op YobicodeOp) error {
+	return j.write(record{Yobicode: &op})
+}
+
+// Interceptor records successful WithdrawCoinsToAddress, CreateYobicode and
+// RedeemYobicode calls (plug in with api.WithInterceptors)
+func (j *Journal) Interceptor() api.Interceptor {
+	return func(ctx context.Context, req *api.Request, next api.Handler) (*api.Response, error) {
+		resp, err := next(ctx, req)
+		if err != nil || resp == nil || !req.Signed {
+			return resp, err
+		}
+
+		r, ok := recordOf(req, resp.Body)
+		if !ok {
+			return resp, err
+		}
+		if werr := j.write(r); werr != nil {
+			j.mu.Lock()
+			if j.err == nil {
+				j.err = werr
+			}
+			j.mu.Unlock()
+			if j.Errors != nil {
+				j.Errors(werr)
+			}
+		}
+
+		return resp, err
+	}
+}
+
+// recordOf returns the record of a successful call, the coupon itself is never recorded
+func recordOf(req *api.Request, body []byte) (record, bool) {
+	now := time.Now().UTC()
+	amount, _ := strconv.ParseFloat(req.Params.Get("amount"), 64)
+
+	switch req.Method {
+	case "WithdrawCoinsToAddress":
+		var resp api.WithdrawCoinsToAddress
+		if api.Unmarshal(body, &resp) != nil || resp.Success != 1 {
+			return record{}, false
+		}
+		w := Withdrawal{Time: now}
+		w.CoinName = strings.ToLower(req.Params.Get("coinName"))
+		w.Amount = amount
+		w.Address = req.Params.Get("address")
+		return record{Withdrawal: &w}, true
+	case "CreateYobicode":
+		var resp api.CreateYobicode
+		if api.Unmarshal(body, &resp) != nil || resp.Success != 1 {
+			return record{}, false
+		}
+		return record{Yobicode: &YobicodeOp{
+			Time:     now,
+			Currency: strings.ToLower(req.Params.Get("coinName")),
+			Amount:   amount,
+		}}, true
+	case "RedeemYobicode":
+		var resp api.RedeemYobicode
+		if api.Unmarshal(body, &resp) != nil || resp.Success != 1 {
+			return record{}, false
+		}
+		return record{Yobicode: &YobicodeOp{
+			Time:     now,
+			Redeem:   true,
+			Currency: strings.ToLower(resp.Return.CouponCurrency),
+			Amount:   resp.Return.CouponAmount,
+		}}, true
+	}
+
+	return record{}, false
+}
+
+// write appends the record to the file and to memory
+func (j *Journal) write(r record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	_, err = j.file.Write(append(data, '\n'))
+	if err == nil {
+		err = j.file.Sync()
+	}
+	if err != nil {
+		return err
+	}
+	j.add(r)
+
+	return nil
+}
+
+// add keeps the record in memory
+func (j *Journal) add(r record) {
+	if r.Withdrawal != nil {
+		j.withdrawals = append(j.withdrawals, *r.Withdrawal)
+	}
+	if r.Yobicode != nil {
+		j.yobicodes = append(j.yobicodes, *r.Yobicode)
+	}
+}
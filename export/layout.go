@@ -0,0 +1,175 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Column is a ledger column
+type Column struct {
+	Header string
+	Value  func(Row) string
+}
+
+// Layout is an ordered set of columns
+type Layout []Column
+
+// fields are columns available by name for custom layouts
+var fields = map[string]func(Row) string{
+	"time":              func(r Row) string { return r.Time.UTC().Format(time.RFC3339) },
+	"kind":              func(r Row) string { return r.Kind },
+	"id":                func(r Row) string { return r.ID },
+	"pair":              func(r Row) string { return r.Pair },
+	"side":              func(r Row) string { return r.Side },
+	"base_currency":     func(r Row) string { return upper(r.BaseCurrency) },
+	"base_amount":       func(r Row) string { return amount(r.BaseAmount) },
+	"quote_currency":    func(r Row) string { return upper(r.QuoteCurrency) },
+	"quote_amount":      func(r Row) string { return amount(r.QuoteAmount) },
+	"sent_currency":     func(r Row) string { return upper(r.SentCurrency) },
+	"sent_amount":       func(r Row) string { return amount(r.SentAmount) },
+	"received_currency": func(r Row) string { return upper(r.ReceivedCurrency) },
+	"received_amount":   func(r Row) string { return amount(r.ReceivedAmount) },
+	"fee_currency":      func(r Row) string { return upper(r.FeeCurrency) },
+	"fee_amount":        func(r Row) string { return amount(r.FeeAmount) },
+	"fiat_currency":     func(r Row) string { return upper(r.FiatCurrency) },
+	"fiat_value":        func(r Row) string { return amount(r.FiatValue) },
+	"note":              func(r Row) string { return r.Note },
+	"tx_hash":           func(r Row) string { return r.TxHash },
+}
+
+// NewLayout builds a layout from field names; a header may be renamed with
+// "field:Header" (example: "time:Date")
+func NewLayout(names ...string) (Layout, error) {
+	layout := make(Layout, 0, len(names))
+	for _, name := range names {
+		field, header := name, name
+		if i := strings.Index(name, ":"); i >= 0 {
+			field, header = name[:i], name[i+1:]
+		}
+		value, ok := fields[field]
+		if !ok {
+			return nil, fmt.Errorf("export: unknown field %q", field)
+		}
+		layout = append(layout, Column{Header: header, Value: value})
+	}
+
+	return layout, nil
+}
+
+// Default lists every field
+var Default = mustLayout(
+	"time", "kind", "id", "pair", "side",
+	"base_currency", "base_amount", "quote_currency", "quote_amount",
+	"fee_currency", "fee_amount", "fiat_currency", "fiat_value", "note", "tx_hash",
+)
+
+// Koinly is the Koinly universal CSV layout
+var Koinly = append(mustLayout(
+	"time:Date",
+	"sent_amount:Sent Amount", "sent_currency:Sent Currency",
+	"received_amount:Received Amount", "received_currency:Received Currency",
+	"fee_amount:Fee Amount", "fee_currency:Fee Currency",
+	"fiat_value:Net Worth Amount", "fiat_currency:Net Worth Currency",
+), Column{Header: "Label", Value: koinlyLabel}, Column{Header: "Description", Value: fields["note"]}, Column{Header: "TxHash", Value: fields["tx_hash"]})
+
+// CoinTracking is the CoinTracking CSV import layout
+var CoinTracking = Layout{
+	{Header: "Type", Value: coinTrackingType},
+	{Header: "Buy Amount", Value: fields["received_amount"]},
+	{Header: "Buy Currency", Value: fields["received_currency"]},
+	{Header: "Sell Amount", Value: fields["sent_amount"]},
+	{Header: "Sell Currency", Value: fields["sent_currency"]},
+	{Header: "Fee", Value: fields["fee_amount"]},
+	{Header: "Fee Currency", Value: fields["fee_currency"]},
+	{Header: "Exchange", Value: func(Row) string { return "Yobit" }},
+	{Header: "Trade-Group", Value: fields["pair"]},
+	{Header: "Comment", Value: fields["note"]},
+	{Header: "Date", Value: func(r Row) string { return r.Time.UTC().Format("2006-01-02 15:04:05") }},
+}
+
+// WriteCSV writes rows as CSV with a header line
+func WriteCSV(w io.Writer, rows []Row, layout Layout) error {
+	cw := csv.NewWriter(w)
+
+	record := make([]string, len(layout))
+	for i, c := range layout {
+		record[i] = c.Header
+	}
+	if err := cw.Write(record); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		for i, c := range layout {
+			record[i] = c.Value(row)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// WriteJSON writes rows as a JSON array of objects keyed by column headers
+func WriteJSON(w io.Writer, rows []Row, layout Layout) error {
+	objects := make([]map[string]string, 0, len(rows))
+	for _, row := range rows {
+		object := make(map[string]string, len(layout))
+		for _, c := range layout {
+			object[c.Header] = c.Value(row)
+		}
+		objects = append(objects, object)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(objects)
+}
+
+func koinlyLabel(r Row) string {
+	switch r.Kind {
+	case KindYobicodeCreate, KindYobicodeRedeem:
+		return "gift"
+	}
+	return ""
+}
+
+func coinTrackingType(r Row) string {
+	switch r.Kind {
+	case KindTrade:
+		return "Trade"
+	case KindWithdrawal:
+		return "Withdrawal"
+	case KindYobicodeCreate:
+		return "Gift"
+	case KindYobicodeRedeem:
+		return "Gift/Tip"
+	}
+	return ""
+}
+
+func mustLayout(names ...string) Layout {
+	layout, err := NewLayout(names...)
+	if err != nil {
+		panic(err)
+	}
+	return layout
+}
+
+func amount(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func upper(s string) string {
+	return strings.ToUpper(s)
+}
@@ -0,0 +1,189 @@
+// Package export turns synced trades, withdrawals and Yobicode operations into
+// CSV and JSON ledgers for tax and accounting tools.
+package export
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	api "github.com/vladivolo/yobit-api"
+	"github.com/vladivolo/yobit-api/historysync"
+	"github.com/vladivolo/yobit-api/yobicode"
+)
+
+// Row kinds
+const (
+	KindTrade          = "trade"
+	KindWithdrawal     = "withdrawal"
+	KindYobicodeCreate = "yobicode_create"
+	KindYobicodeRedeem = "yobicode_redeem"
+)
+
+// Withdrawal is a recorded WithdrawCoinsToAddress submission
+type Withdrawal struct {
+	Time   time.Time `json:"time"`
+	TxHash string    `json:"tx_hash,omitempty"` // blockchain transaction hash, if known (the API doesn't return it)
+	api.WithdrawCoinsToAddressSettings
+}
+
+// YobicodeOp is a recorded Yobicode creation or redemption
+type YobicodeOp struct {
+	Time     time.Time `json:"time"`
+	Redeem   bool      `json:"redeem"`   // false - created, true - redeemed
+	Currency string    `json:"currency"` // coin of the coupon
+	Amount   float64   `json:"amount"`   // coupon amount
+	Label    string    `json:"label"`    // caller label, the coupon itself is never exported
+}
+
+// YobicodeOps returns the operations of yobicode ledger entries: the creation of
+// coupons created through the ledger and the redemption of redeemed ones
+func YobicodeOps(entries []yobicode.Entry) []YobicodeOp {
+	var ops []YobicodeOp
+	for _, e := range entries {
+		op := YobicodeOp{Currency: e.Currency, Amount: e.Amount, Label: e.Label}
+		if !e.Created.IsZero() {
+			op.Time = e.Created
+			ops = append(ops, op)
+		}
+		if e.Redeemed {
+			op.Time, op.Redeem = e.RedeemedAt, true
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+// Row is a ledger entry
+type Row struct {
+	Time             time.Time
+	Kind             string  // trade, withdrawal, yobicode_create or yobicode_redeem
+	ID               string  // trade ID, empty for other kinds
+	Pair             string  // pair of a trade
+	Side             string  // buy or sell for trades
+	BaseCurrency     string  // base currency of a trade, the coin of other kinds
+	BaseAmount       float64 // amount in base currency
+	QuoteCurrency    string  // quote currency of a trade
+	QuoteAmount      float64 // amount in quote currency
+	SentCurrency     string  // coin given
+	SentAmount       float64 // amount given
+	ReceivedCurrency string  // coin received
+	ReceivedAmount   float64 // amount received after fee
+	FeeCurrency      string  // coin the fee was taken in
+	FeeAmount        float64 // fee amount
+	FiatCurrency     string  // currency of FiatValue
+	FiatValue        float64 // value of the sent side at the time (0 - unknown)
+	Note             string  // address of a withdrawal or label of a Yobicode
+	TxHash           string  // blockchain transaction hash of a withdrawal, if known
+}
+
+// Ledger builds rows
+type Ledger struct {
+	info   api.Info
+	prices *PriceHistory
+	fiat   string
+
+	rows []Row
+}
+
+// NewLedger is a constructor for the Ledger. Pair fees are taken from info, fiat values
+// in currency fiat (example: usd) from recorded prices; prices may be nil.
+func NewLedger(info api.Info, prices *PriceHistory, fiat string) *Ledger {
+	return &Ledger{
+		info:   info,
+		prices: prices,
+		fiat:   fiat,
+	}
+}
+
+// AddTrades adds synced trades
+func (l *Ledger) AddTrades(records []historysync.Record) {
+	for _, r := range records {
+		base, quote := api.SplitPair(r.Pair)
+		fee := l.info.Fee(r.Pair) / 100
+		row := Row{
+			Time:          r.Time(),
+			Kind:          KindTrade,
+			ID:            strconv.FormatUint(r.ID, 10),
+			Pair:          r.Pair,
+			Side:          r.Type,
+			BaseCurrency:  base,
+			BaseAmount:    r.Amount,
+			QuoteCurrency: quote,
+			QuoteAmount:   r.Amount * r.Rate,
+		}
+		// the fee is taken from the coins received
+		if r.Type == "buy" {
+			row.SentCurrency, row.SentAmount = quote, row.QuoteAmount
+			row.ReceivedCurrency, row.ReceivedAmount = base, r.Amount*(1-fee)
+			row.FeeCurrency, row.FeeAmount = base, r.Amount*fee
+		} else {
+			row.SentCurrency, row.SentAmount = base, r.Amount
+			row.ReceivedCurrency, row.ReceivedAmount = quote, row.QuoteAmount*(1-fee)
+			row.FeeCurrency, row.FeeAmount = quote, row.QuoteAmount*fee
+		}
+		l.add(row)
+	}
+}
+
+// AddWithdrawals adds withdrawal submissions
+func (l *Ledger) AddWithdrawals(withdrawals []Withdrawal) {
+	for _, w := range withdrawals {
+		l.add(Row{
+			Time:         w.Time,
+			Kind:         KindWithdrawal,
+			BaseCurrency: w.CoinName,
+			BaseAmount:   w.Amount,
+			SentCurrency: w.CoinName,
+			SentAmount:   w.Amount,
+			Note:         w.Address,
+			TxHash:       w.TxHash,
+		})
+	}
+}
+
+// AddYobicodes adds Yobicode operations
+func (l *Ledger) AddYobicodes(ops []YobicodeOp) {
+	for _, op := range ops {
+		row := Row{
+			Time:         op.Time,
+			Kind:         KindYobicodeCreate,
+			BaseCurrency: op.Currency,
+			BaseAmount:   op.Amount,
+			SentCurrency: op.Currency,
+			SentAmount:   op.Amount,
+			Note:         op.Label,
+		}
+		if op.Redeem {
+			row.Kind = KindYobicodeRedeem
+			row.SentCurrency, row.SentAmount = "", 0
+			row.ReceivedCurrency, row.ReceivedAmount = op.Currency, op.Amount
+		}
+		l.add(row)
+	}
+}
+
+// Rows returns all rows ordered by time
+func (l *Ledger) Rows() []Row {
+	rows := append([]Row(nil), l.rows...)
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Time.Before(rows[j].Time)
+	})
+	return rows
+}
+
+// add values the row in fiat and appends it
+func (l *Ledger) add(row Row) {
+	coin, amount := row.SentCurrency, row.SentAmount
+	if coin == "" {
+		coin, amount = row.ReceivedCurrency, row.ReceivedAmount
+	}
+	if l.prices != nil && l.fiat != "" {
+		if rate, ok := l.prices.Rate(coin, l.fiat, row.Time); ok {
+			row.FiatCurrency = l.fiat
+			row.FiatValue = amount * rate
+		}
+	}
+
+	l.rows = append(l.rows, row)
+}
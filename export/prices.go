@@ -0,0 +1,137 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// Snapshot is a recorded last price of a pair
+type Snapshot struct {
+	Time  time.Time `json:"time"`
+	Pair  string    `json:"pair"`
+	Price float64   `json:"price"`
+}
+
+// DefaultMaxAge is the default PriceHistory.MaxAge
+const DefaultMaxAge = 24 * time.Hour
+
+// PriceHistory keeps recorded ticker prices for valuation at a past time
+type PriceHistory struct {
+	// MaxAge is the oldest snapshot used for a time, older prices are unknown (0 - any age)
+	MaxAge time.Duration
+
+	mu     sync.RWMutex
+	prices map[string][]Snapshot // by pair, ordered by time
+}
+
+// NewPriceHistory is a constructor for the PriceHistory with DefaultMaxAge
+func NewPriceHistory() *PriceHistory {
+	return &PriceHistory{
+		MaxAge: DefaultMaxAge,
+		prices: make(map[string][]Snapshot),
+	}
+}
+
+// RecordTicker records last prices of all pairs of the ticker at time t
+func (h *PriceHistory) RecordTicker(t time.Time, ticker api.Ticker) {
+	for pair, data := range ticker.PairData {
+		if data.Last > 0 {
+			h.Record(Snapshot{Time: t, Pair: pair, Price: data.Last})
+		}
+	}
+}
+
+// Record adds a snapshot
+func (h *PriceHistory) Record(s Snapshot) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	snapshots := h.prices[s.Pair]
+	i := sort.Search(len(snapshots), func(i int) bool {
+		return snapshots[i].Time.After(s.Time)
+	})
+	snapshots = append(snapshots, Snapshot{})
+	copy(snapshots[i+1:], snapshots[i:])
+	snapshots[i] = s
+	h.prices[s.Pair] = snapshots
+}
+
+// Price returns the last price of the pair recorded at or before t, no older than MaxAge
+func (h *PriceHistory) Price(pair string, t time.Time) (float64, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	snapshots := h.prices[pair]
+	i := sort.Search(len(snapshots), func(i int) bool {
+		return snapshots[i].Time.After(t)
+	})
+	if i == 0 {
+		return 0, false
+	}
+	last := snapshots[i-1]
+	if h.MaxAge > 0 && t.Sub(last.Time) > h.MaxAge {
+		return 0, false
+	}
+
+	return last.Price, true
+}
+
+// Rate returns the value of one coin in currency at time t through a direct pair,
+// an inverse pair or a pair with btc
+func (h *PriceHistory) Rate(coin, currency string, t time.Time) (float64, bool) {
+	if coin == currency {
+		return 1, true
+	}
+	if price, ok := h.Price(coin+"_"+currency, t); ok && price > 0 {
+		return price, true
+	}
+	if price, ok := h.Price(currency+"_"+coin, t); ok && price > 0 {
+		return 1 / price, true
+	}
+	if coin != "btc" && currency != "btc" {
+		a, ok := h.Rate(coin, "btc", t)
+		if !ok {
+			return 0, false
+		}
+		b, ok := h.Rate("btc", currency, t)
+		if !ok {
+			return 0, false
+		}
+		return a * b, true
+	}
+
+	return 0, false
+}
+
+// Save writes all snapshots as JSON
+func (h *PriceHistory) Save(w io.Writer) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var snapshots []Snapshot
+	for _, s := range h.prices {
+		snapshots = append(snapshots, s...)
+	}
+
+	return json.NewEncoder(w).Encode(snapshots)
+}
+
+// Load reads snapshots written by Save
+func (h *PriceHistory) Load(r io.Reader) error {
+	var snapshots []Snapshot
+	err := json.NewDecoder(r).Decode(&snapshots)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range snapshots {
+		h.Record(s)
+	}
+
+	return nil
+}
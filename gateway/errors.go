@@ -0,0 +1,118 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+
+	api "github.com/vladivolo/yobit-api"
+	"github.com/vladivolo/yobit-api/address"
+	"github.com/vladivolo/yobit-api/withdrawal"
+)
+
+// Types of errors
+const (
+	TypeBadRequest       = "bad_request"        // invalid parameters or body of the request
+	TypeUnauthorized     = "unauthorized"       // missing or unknown token
+	TypeForbidden        = "forbidden"          // the token has no permission for the endpoint
+	TypeNotFound         = "not_found"          // no such endpoint
+	TypeMethodNotAllowed = "method_not_allowed" // the endpoint has no such method, see the Allow header
+	TypeInvalidAddress   = "invalid_address"    // the withdrawal address failed validation
+	TypePolicy           = "policy"             // the withdrawal guard refused the withdrawal
+	TypeAPI              = "api"                // Yobit rejected the request (*api.APIError)
+	TypeHTTP             = "http"               // Yobit answered with an HTTP error status (*api.HTTPError)
+	TypeDecode           = "decode"             // the response of Yobit couldn't be decoded (*api.DecodeError)
+	TypeTimeout          = "timeout"            // the request timed out or was cancelled
+	TypeTransport        = "transport"          // Yobit couldn't be reached
+	TypeInternal         = "internal"           // any other error
+)
+
+// Error is the body of error responses, {"error": Error}
+type Error struct {
+	Type    string `json:"type"`             // one of the Type constants
+	Message string `json:"message"`          // error text
+	Status  int    `json:"status,omitempty"` // HTTP status of Yobit, TypeHTTP only
+	Path    string `json:"path,omitempty"`   // path of the response field, TypeDecode only
+	Reason  string `json:"reason,omitempty"` // reason of the address error, TypeInvalidAddress only
+}
+
+func (e *Error) Error() string {
+	return "gateway: " + e.Type + ": " + e.Message
+}
+
+// httpStatus returns the HTTP status of the gateway response
+func (e *Error) httpStatus() int {
+	switch e.Type {
+	case TypeBadRequest, TypeInvalidAddress:
+		return http.StatusBadRequest
+	case TypeUnauthorized:
+		return http.StatusUnauthorized
+	case TypeForbidden, TypePolicy:
+		return http.StatusForbidden
+	case TypeNotFound:
+		return http.StatusNotFound
+	case TypeMethodNotAllowed:
+		return http.StatusMethodNotAllowed
+	case TypeAPI:
+		return http.StatusUnprocessableEntity
+	case TypeHTTP, TypeDecode, TypeTransport:
+		return http.StatusBadGateway
+	case TypeTimeout:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+func badRequest(message string) *Error {
+	return &Error{Type: TypeBadRequest, Message: message}
+}
+
+// errorOf converts the typed errors of the library to an Error
+func errorOf(err error) *Error {
+	var (
+		gwErr      *Error
+		apiErr     *api.APIError
+		httpErr    *api.HTTPError
+		decodeErr  *api.DecodeError
+		addressErr *address.Error
+		netErr     net.Error
+	)
+
+	switch {
+	case errors.As(err, &gwErr):
+		return gwErr
+	case errors.Is(err, withdrawal.ErrNotAllowed), errors.Is(err, withdrawal.ErrCoolingOff),
+		errors.Is(err, withdrawal.ErrLimitExceeded), errors.Is(err, withdrawal.ErrTwoStepEnabled):
+		return &Error{Type: TypePolicy, Message: err.Error()}
+	case errors.As(err, &addressErr):
+		return &Error{Type: TypeInvalidAddress, Message: err.Error(), Reason: addressErr.Reason.Error()}
+	case errors.As(err, &apiErr):
+		return &Error{Type: TypeAPI, Message: apiErr.Message}
+	case errors.As(err, &httpErr):
+		return &Error{Type: TypeHTTP, Message: err.Error(), Status: httpErr.Status}
+	case errors.As(err, &decodeErr):
+		return &Error{Type: TypeDecode, Message: err.Error(), Path: decodeErr.Path}
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return &Error{Type: TypeTimeout, Message: err.Error()}
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return &Error{Type: TypeTimeout, Message: err.Error()}
+		}
+		return &Error{Type: TypeTransport, Message: err.Error()}
+	}
+	return &Error{Type: TypeInternal, Message: err.Error()}
+}
+
+func writeError(w http.ResponseWriter, e *Error) {
+	writeJSON(w, e.httpStatus(), struct {
+		Error *Error `json:"error"`
+	}{e})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
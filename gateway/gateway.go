@@ -0,0 +1,245 @@
+// Package gateway is a local HTTP server that keeps the API secrets of a Client
+// in one process and lets internal tools use the API through JSON endpoints.
+//
+// Callers authenticate with their own bearer tokens, each with a set of permissions:
+// read (market data and account state), trade (orders and redeeming yobicodes) and
+// withdraw (withdrawals and creating yobicodes). Requests of the Trade API are
+// serialised, so callers never race for the nonce.
+//
+// Withdrawals are checked by the address validator (on default: address.NewRegistry)
+// and sent through the withdrawal.Guard given with WithGuard; without a guard the
+// withdrawal endpoint is disabled.
+//
+// Endpoints:
+//
+//	GET    /v1/info                          read
+//	GET    /v1/ticker?pair=ltc_btc,eth_btc   read
+//	GET    /v1/depth/{pair}?limit=n          read
+//	GET    /v1/trades/{pair}?limit=n         read
+//	GET    /v1/balance                       read
+//	GET    /v1/orders?pair=ltc_btc           read
+//	GET    /v1/orders/{id}                   read
+//	GET    /v1/history?pair=ltc_btc&...      read
+//	GET    /v1/deposit-address/{coin}        read
+//	GET    /v1/deposit-address/{coin}?new=1  trade or withdraw
+//	POST   /v1/orders                        trade     {"pair", "type", "rate", "amount"}
+//	DELETE /v1/orders/{id}                   trade
+//	POST   /v1/yobicodes/redeem              trade     {"coupon"}
+//	POST   /v1/withdrawals                   withdraw  {"coin_name", "amount", "address"}
+//	POST   /v1/yobicodes                     withdraw  {"coin_name", "amount"}
+//
+// Successful responses are the "return" part of the API response. Errors are
+// {"error": {"type": "...", "message": "..."}}, see Error.
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	api "github.com/vladivolo/yobit-api"
+	"github.com/vladivolo/yobit-api/address"
+	"github.com/vladivolo/yobit-api/withdrawal"
+)
+
+// Permission is a permission of a caller token
+type Permission string
+
+const (
+	PermRead     Permission = "read"     // market data, balances, orders and history
+	PermTrade    Permission = "trade"    // placing and cancelling orders, redeeming yobicodes
+	PermWithdraw Permission = "withdraw" // withdrawals and creating yobicodes
+)
+
+// Token is a caller of the gateway
+type Token struct {
+	Name        string       `json:"name"`        // name of the caller
+	Hash        string       `json:"hash"`        // hex SHA-256 of the bearer token, see HashToken
+	Permissions []Permission `json:"permissions"` // granted permissions, trade and withdraw include read
+}
+
+// allows reports whether the token has the permission
+func (t *Token) allows(p Permission) bool {
+	for _, granted := range t.Permissions {
+		if granted == p || p == PermRead {
+			return true
+		}
+	}
+	return false
+}
+
+// HashToken returns the hex SHA-256 of a bearer token for Token.Hash
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Server is the gateway, an http.Handler
+type Server struct {
+	client    *api.Client
+	tokens    map[string]Token // by hash
+	mux       *http.ServeMux
+	guard     *withdrawal.Guard    // sends withdrawals, nil disables them
+	validator api.AddressValidator // checks withdrawal addresses
+
+	trade sync.Mutex // serialises requests of the Trade API, they share the nonce
+}
+
+// Option configures the Server
+type Option func(*Server)
+
+// WithGuard enables withdrawals, sent through the guard. The guard should use the
+// Trade API of the gateway client, so its requests are serialised too.
+func WithGuard(g *withdrawal.Guard) Option {
+	return func(s *Server) {
+		s.guard = g
+	}
+}
+
+// WithAddressValidator replaces the default validator of withdrawal addresses
+func WithAddressValidator(v api.AddressValidator) Option {
+	return func(s *Server) {
+		s.validator = v
+	}
+}
+
+// New returns the gateway to the client for the tokens
+func New(client *api.Client, tokens []Token, opts ...Option) (*Server, error) {
+	s := &Server{
+		client:    client,
+		tokens:    make(map[string]Token, len(tokens)),
+		mux:       http.NewServeMux(),
+		validator: address.NewRegistry(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	for _, t := range tokens {
+		hash := strings.ToLower(t.Hash)
+		if len(hash) != sha256.Size*2 {
+			return nil, fmt.Errorf("gateway: token %q: hash must be a hex SHA-256", t.Name)
+		}
+		for _, p := range t.Permissions {
+			if p != PermRead && p != PermTrade && p != PermWithdraw {
+				return nil, fmt.Errorf("gateway: token %q: unknown permission %q", t.Name, p)
+			}
+		}
+		if _, ok := s.tokens[hash]; ok {
+			return nil, fmt.Errorf("gateway: token %q: duplicate hash", t.Name)
+		}
+		s.tokens[hash] = t
+	}
+
+	s.handle("GET /v1/info", PermRead, s.info)
+	s.handle("GET /v1/ticker", PermRead, s.ticker)
+	s.handle("GET /v1/depth/{pair}", PermRead, s.depth)
+	s.handle("GET /v1/trades/{pair}", PermRead, s.trades)
+	s.handle("GET /v1/balance", PermRead, s.balance)
+	s.handle("GET /v1/orders", PermRead, s.orders)
+	s.handle("GET /v1/orders/{id}", PermRead, s.order)
+	s.handle("GET /v1/history", PermRead, s.history)
+	s.handle("GET /v1/deposit-address/{coin}", PermRead, s.depositAddress)
+	s.handle("POST /v1/orders", PermTrade, s.placeOrder)
+	s.handle("DELETE /v1/orders/{id}", PermTrade, s.cancelOrder)
+	s.handle("POST /v1/yobicodes/redeem", PermTrade, s.redeemYobicode)
+	s.handle("POST /v1/withdrawals", PermWithdraw, s.withdraw)
+	s.handle("POST /v1/yobicodes", PermWithdraw, s.createYobicode)
+
+	return s, nil
+}
+
+// ServeHTTP authenticates the caller and serves the request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, pattern := s.mux.Handler(r); pattern == "" {
+		if allow := s.allowed(r); len(allow) > 0 {
+			w.Header().Set("Allow", strings.Join(allow, ", "))
+			writeError(w, &Error{Type: TypeMethodNotAllowed, Message: "method " + r.Method + " not allowed on " + r.URL.Path})
+			return
+		}
+		writeError(w, &Error{Type: TypeNotFound, Message: "no endpoint " + r.Method + " " + r.URL.Path})
+		return
+	}
+	// the mux sets the path values, the handler returned by Handler doesn't
+	s.mux.ServeHTTP(w, r)
+}
+
+// allowed returns the methods of the endpoints at the path of the request
+func (s *Server) allowed(r *http.Request) []string {
+	var methods []string
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodDelete} {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		if _, pattern := s.mux.Handler(probe); pattern != "" {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
+// handlerFunc serves an authenticated request with a client bound to its context
+type handlerFunc func(c *api.Client, r *http.Request) (interface{}, error)
+
+func (s *Server) handle(pattern string, perm Permission, h handlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		token, ok := s.authenticate(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, &Error{Type: TypeUnauthorized, Message: "missing or unknown bearer token"})
+			return
+		}
+		if !token.allows(perm) {
+			writeError(w, &Error{Type: TypeForbidden, Message: fmt.Sprintf("token %q has no %s permission", token.Name, perm)})
+			return
+		}
+
+		r = r.WithContext(context.WithValue(r.Context(), tokenKey{}, token))
+		result, err := h(s.bind(r), r)
+		if err != nil {
+			writeError(w, errorOf(err))
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	})
+}
+
+// tokenKey is the context key of the authenticated token
+type tokenKey struct{}
+
+// tokenOf returns the authenticated token of the request
+func tokenOf(r *http.Request) Token {
+	token, _ := r.Context().Value(tokenKey{}).(Token)
+	return token
+}
+
+// authenticate returns the token of the Authorization header
+func (s *Server) authenticate(r *http.Request) (Token, bool) {
+	auth := r.Header.Get("Authorization")
+	if len(auth) < 7 || !strings.EqualFold(auth[:7], "bearer ") {
+		return Token{}, false
+	}
+	token, ok := s.tokens[HashToken(strings.TrimSpace(auth[7:]))]
+	return token, ok
+}
+
+// bind returns a copy of the client bound to the request context, which
+// reports HTTP error statuses of Yobit as *api.HTTPError
+func (s *Server) bind(r *http.Request) *api.Client {
+	c := s.client.WithContext(r.Context())
+	c.Public.Interceptors = append(append([]api.Interceptor(nil), c.Public.Interceptors...), failStatus)
+	c.Private.Interceptors = append(append([]api.Interceptor(nil), c.Private.Interceptors...), failStatus)
+	return c
+}
+
+// failStatus turns HTTP error statuses into *api.HTTPError instead of undecodable bodies
+func failStatus(ctx context.Context, req *api.Request, next api.Handler) (*api.Response, error) {
+	resp, err := next(ctx, req)
+	if err == nil && resp != nil && resp.Status >= 400 {
+		return nil, &api.HTTPError{Status: resp.Status}
+	}
+	return resp, err
+}
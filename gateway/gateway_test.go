@@ -0,0 +1,155 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/vladivolo/yobit-api"
+	"github.com/vladivolo/yobit-api/withdrawal"
+)
+
+// responses are the bodies answered by fakeYobit by method
+var responses = map[string]string{
+	"ticker":                 `{"ltc_btc":{"last":0.01}}`,
+	"getInfo":                `{"success":1,"return":{"funds":{"btc":1}}}`,
+	"GetDepositAddress":      `{"success":1,"return":{"address":"1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2","processed_amount":0}}`,
+	"Trade":                  `{"success":1,"return":{"received":0,"remains":1,"order_id":7,"funds":{}}}`,
+	"CancelOrder":            `{"success":1,"return":{"order_id":7,"funds":{}}}`,
+	"RedeemYobicode":         `{"success":1,"return":{"couponAmount":1,"couponCurrency":"btc","transID":1,"funds":{}}}`,
+	"WithdrawCoinsToAddress": `{"success":1,"return":{"server_time":1700000000}}`,
+}
+
+// fakeYobit answers requests instead of the API server, unknown pairs get the
+// error body of the Public API
+func fakeYobit(ctx context.Context, req *api.Request, next api.Handler) (*api.Response, error) {
+	if strings.Contains(req.Pair, "xxx") {
+		return &api.Response{Status: 200, Body: []byte(`{"success":0,"error":"Invalid pair name: ltc_xxx"}`)}, nil
+	}
+	return &api.Response{Status: 200, Body: []byte(responses[req.Method])}, nil
+}
+
+const (
+	allowed    = "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2"
+	notAllowed = "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy"
+)
+
+func newServer(t *testing.T, guarded bool) *Server {
+	client := api.NewClient("testgatewaykey", "secret", api.WithInterceptors(fakeYobit))
+
+	var opts []Option
+	if guarded {
+		guard, err := withdrawal.NewGuard(client.Private, withdrawal.Config{
+			Addresses: map[string][]withdrawal.Address{"btc": {{Address: allowed, Added: time.Now().Add(-48 * time.Hour)}}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts = append(opts, WithGuard(guard))
+	}
+
+	s, err := New(client, []Token{
+		{Name: "reader", Hash: HashToken("r"), Permissions: []Permission{PermRead}},
+		{Name: "trader", Hash: HashToken("t"), Permissions: []Permission{PermTrade}},
+		{Name: "withdrawer", Hash: HashToken("w"), Permissions: []Permission{PermWithdraw}},
+	}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestPermissions(t *testing.T) {
+	tests := []struct {
+		name    string
+		guarded bool
+		token   string
+		method  string
+		path    string
+		body    string
+		status  int
+		errType string
+	}{
+		{"no endpoint", false, "r", "GET", "/v1/nothing", "", 404, TypeNotFound},
+		{"wrong method", false, "r", "PUT", "/v1/balance", "", 405, TypeMethodNotAllowed},
+		{"no token", false, "", "GET", "/v1/balance", "", 401, TypeUnauthorized},
+		{"unknown token", false, "x", "GET", "/v1/balance", "", 401, TypeUnauthorized},
+		{"read balance", false, "r", "GET", "/v1/balance", "", 200, ""},
+		{"read deposit address", false, "r", "GET", "/v1/deposit-address/btc", "", 200, ""},
+		{"read new deposit address", false, "r", "GET", "/v1/deposit-address/btc?new=1", "", 403, TypeForbidden},
+		{"trade new deposit address", false, "t", "GET", "/v1/deposit-address/btc?new=1", "", 200, ""},
+		{"withdraw new deposit address", false, "w", "GET", "/v1/deposit-address/btc?new=true", "", 200, ""},
+		{"read order", false, "r", "POST", "/v1/orders", `{"pair":"ltc_btc","type":"buy","rate":0.01,"amount":1}`, 403, TypeForbidden},
+		{"trade order", false, "t", "POST", "/v1/orders", `{"pair":"ltc_btc","type":"buy","rate":0.01,"amount":1}`, 200, ""},
+		{"withdraw order", false, "w", "DELETE", "/v1/orders/7", "", 403, TypeForbidden},
+		{"trade cancel", false, "t", "DELETE", "/v1/orders/7", "", 200, ""},
+		{"trade redeem", false, "t", "POST", "/v1/yobicodes/redeem", `{"coupon":"YOBITAAAAAAAAAAAAAAAAAAAABTC"}`, 200, ""},
+		{"trade redeem invalid coupon", false, "t", "POST", "/v1/yobicodes/redeem", `{"coupon":"yobit-abc"}`, 400, TypeBadRequest},
+		{"trade withdrawal", true, "t", "POST", "/v1/withdrawals", `{"coin_name":"btc","amount":1,"address":"` + allowed + `"}`, 403, TypeForbidden},
+		{"withdrawal without guard", false, "w", "POST", "/v1/withdrawals", `{"coin_name":"btc","amount":1,"address":"` + allowed + `"}`, 403, TypeForbidden},
+		{"withdrawal", true, "w", "POST", "/v1/withdrawals", `{"coin_name":"btc","amount":1,"address":"` + allowed + `"}`, 200, ""},
+		{"withdrawal not allowed", true, "w", "POST", "/v1/withdrawals", `{"coin_name":"btc","amount":1,"address":"` + notAllowed + `"}`, 403, TypePolicy},
+		{"withdrawal invalid address", true, "w", "POST", "/v1/withdrawals", `{"coin_name":"btc","amount":1,"address":"1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN3"}`, 400, TypeInvalidAddress},
+		{"ticker", false, "r", "GET", "/v1/ticker?pair=ltc_btc", "", 200, ""},
+		{"ticker error body", false, "r", "GET", "/v1/ticker?pair=ltc_xxx", "", 422, TypeAPI},
+		{"depth error body", false, "r", "GET", "/v1/depth/ltc_xxx", "", 422, TypeAPI},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t, tt.guarded)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.errType == "" {
+				return
+			}
+			var body struct {
+				Error Error `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Error.Type != tt.errType {
+				t.Fatalf("error = %+v, want type %s", body.Error, tt.errType)
+			}
+		})
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		allow  string
+	}{
+		{"POST", "/v1/balance", "GET"},
+		{"PUT", "/v1/orders", "GET, POST"},
+		{"POST", "/v1/orders/7", "GET, DELETE"},
+	}
+
+	s := newServer(t, false)
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer r")
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, req)
+
+			if w.Code != 405 || w.Header().Get("Allow") != tt.allow {
+				t.Fatalf("status %d, Allow %q; want 405 and %q", w.Code, w.Header().Get("Allow"), tt.allow)
+			}
+		})
+	}
+}
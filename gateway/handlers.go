@@ -0,0 +1,390 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	api "github.com/vladivolo/yobit-api"
+	"github.com/vladivolo/yobit-api/yobicode"
+)
+
+// serial runs a request of the Trade API while no other one is running
+func (s *Server) serial(f func() error) error {
+	s.trade.Lock()
+	defer s.trade.Unlock()
+	return f()
+}
+
+// failed returns the *api.APIError of a response with success 0
+func failed(success uint8, message string) error {
+	if success != 0 {
+		return nil
+	}
+	if message == "" {
+		message = "request failed"
+	}
+	return &api.APIError{Message: message}
+}
+
+// publicError returns the *api.APIError of a Public API response, they have no success field
+// and the library sets Error from error bodies
+func publicError(message string) error {
+	if message == "" {
+		return nil
+	}
+	return &api.APIError{Message: message}
+}
+
+func (s *Server) info(c *api.Client, r *http.Request) (interface{}, error) {
+	info, err := c.Market().Info()
+	if err != nil {
+		return nil, err
+	}
+	if err := publicError(info.Error); err != nil {
+		return nil, err
+	}
+
+	return struct {
+		ServerTime int64                             `json:"server_time"`
+		Pairs      map[string]map[string]interface{} `json:"pairs"`
+	}{info.ServerTime.Unix(), info.Pairs}, nil
+}
+
+func (s *Server) ticker(c *api.Client, r *http.Request) (interface{}, error) {
+	pairs := pairsParam(r)
+	if len(pairs) == 0 {
+		return nil, badRequest("pair is required")
+	}
+
+	ticker, err := c.Market().Ticker(&api.TickerSettings{Pairs: pairs})
+	if err != nil {
+		return nil, err
+	}
+	if err := publicError(ticker.Error); err != nil {
+		return nil, err
+	}
+	return ticker.PairData, nil
+}
+
+func (s *Server) depth(c *api.Client, r *http.Request) (interface{}, error) {
+	pair := r.PathValue("pair")
+	limit, err := uintParam(r, "limit")
+	if err != nil {
+		return nil, err
+	}
+
+	depth, err := c.Market().Depth(&api.DepthSettings{Pair: pair, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	if err := publicError(depth.Error); err != nil {
+		return nil, err
+	}
+	return depth.PairData[pair], nil
+}
+
+func (s *Server) trades(c *api.Client, r *http.Request) (interface{}, error) {
+	pair := r.PathValue("pair")
+	limit, err := uintParam(r, "limit")
+	if err != nil {
+		return nil, err
+	}
+
+	trades, err := c.Market().Trades(&api.TradesSettings{Pair: pair, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	if err := publicError(trades.Error); err != nil {
+		return nil, err
+	}
+	return trades.PairData[pair], nil
+}
+
+func (s *Server) balance(c *api.Client, r *http.Request) (interface{}, error) {
+	var info api.GetInfo
+	err := s.serial(func() (err error) {
+		info, err = c.Trading().GetInfo()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := failed(info.Success, info.Error); err != nil {
+		return nil, err
+	}
+	return info.Return, nil
+}
+
+func (s *Server) orders(c *api.Client, r *http.Request) (interface{}, error) {
+	pair := r.URL.Query().Get("pair")
+	if pair == "" {
+		return nil, badRequest("pair is required")
+	}
+
+	var orders api.ActiveOrders
+	err := s.serial(func() (err error) {
+		orders, err = c.Trading().ActiveOrders(&api.ActiveOrdersSettings{Pair: pair})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := failed(orders.Success, orders.Error); err != nil {
+		return nil, err
+	}
+	return orders.Return, nil
+}
+
+func (s *Server) order(c *api.Client, r *http.Request) (interface{}, error) {
+	id, err := idParam(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var order api.OrderInfo
+	err = s.serial(func() (err error) {
+		order, err = c.Trading().OrderInfo(&api.OrderInfoSettings{OrderID: id})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := failed(order.Success, order.Error); err != nil {
+		return nil, err
+	}
+	return order.Return, nil
+}
+
+func (s *Server) history(c *api.Client, r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+	settings := &api.TradeHistorySettings{Pair: q.Get("pair"), Order: strings.ToUpper(q.Get("order"))}
+	if settings.Pair == "" {
+		return nil, badRequest("pair is required")
+	}
+	if settings.Order != "" && settings.Order != "ASC" && settings.Order != "DESC" {
+		return nil, badRequest("order must be ASC or DESC")
+	}
+	for name, field := range map[string]*uint64{
+		"from": &settings.From, "count": &settings.Count, "from_id": &settings.FromID,
+		"end_id": &settings.EndID, "since": &settings.Since, "end": &settings.End,
+	} {
+		v, err := uintParam(r, name)
+		if err != nil {
+			return nil, err
+		}
+		*field = v
+	}
+
+	var history api.TradeHistory
+	err := s.serial(func() (err error) {
+		history, err = c.Trading().TradeHistory(settings)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := failed(history.Success, history.Error); err != nil {
+		return nil, err
+	}
+	return history.Return, nil
+}
+
+func (s *Server) depositAddress(c *api.Client, r *http.Request) (interface{}, error) {
+	settings := &api.GetDepositAddressSettings{CoinName: r.PathValue("coin")}
+	if v := r.URL.Query().Get("new"); v == "1" || v == "true" {
+		// a new address changes the account, reading one doesn't
+		if token := tokenOf(r); !token.allows(PermTrade) && !token.allows(PermWithdraw) {
+			return nil, &Error{Type: TypeForbidden, Message: fmt.Sprintf("token %q needs the trade or withdraw permission for a new address", token.Name)}
+		}
+		settings.NeedNew = 1
+	}
+
+	var address api.GetDepositAddress
+	err := s.serial(func() (err error) {
+		address, err = c.Trading().GetDepositAddress(settings)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := failed(address.Success, address.Error); err != nil {
+		return nil, err
+	}
+	return address.Return, nil
+}
+
+func (s *Server) placeOrder(c *api.Client, r *http.Request) (interface{}, error) {
+	var settings api.TradeSettings
+	if err := readBody(r, &settings); err != nil {
+		return nil, err
+	}
+	switch {
+	case settings.Pair == "":
+		return nil, badRequest("pair is required")
+	case settings.Type != "buy" && settings.Type != "sell":
+		return nil, badRequest("type must be buy or sell")
+	case settings.Rate <= 0 || settings.Amount <= 0:
+		return nil, badRequest("rate and amount must be positive")
+	}
+
+	var trade api.Trade
+	err := s.serial(func() (err error) {
+		trade, err = c.Trading().Trade(&settings)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := failed(trade.Success, trade.Error); err != nil {
+		return nil, err
+	}
+	return trade.Return, nil
+}
+
+func (s *Server) cancelOrder(c *api.Client, r *http.Request) (interface{}, error) {
+	id, err := idParam(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var cancel api.CancelOrder
+	err = s.serial(func() (err error) {
+		cancel, err = c.Trading().CancelOrder(&api.CancelOrderSettings{OrderID: id})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := failed(cancel.Success, cancel.Error); err != nil {
+		return nil, err
+	}
+	return cancel.Return, nil
+}
+
+func (s *Server) redeemYobicode(c *api.Client, r *http.Request) (interface{}, error) {
+	var settings api.RedeemYobicodeSettings
+	if err := readBody(r, &settings); err != nil {
+		return nil, err
+	}
+	if err := yobicode.ValidateCoupon(settings.Coupon); err != nil {
+		return nil, badRequest(err.Error())
+	}
+
+	var redeem api.RedeemYobicode
+	err := s.serial(func() (err error) {
+		redeem, err = c.Trading().RedeemYobicode(&settings)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := failed(redeem.Success, redeem.Error); err != nil {
+		return nil, err
+	}
+	return redeem.Return, nil
+}
+
+func (s *Server) withdraw(c *api.Client, r *http.Request) (interface{}, error) {
+	var settings api.WithdrawCoinsToAddressSettings
+	if err := readBody(r, &settings); err != nil {
+		return nil, err
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, badRequest(err.Error())
+	}
+	if s.guard == nil {
+		return nil, &Error{Type: TypeForbidden, Message: "withdrawals are disabled, the gateway has no withdrawal guard"}
+	}
+	if err := s.validator.ValidateAddress(settings.CoinName, settings.Address); err != nil {
+		return nil, err
+	}
+
+	var withdraw api.WithdrawCoinsToAddress
+	err := s.serial(func() (err error) {
+		withdraw, err = s.guard.Withdraw(&settings)
+		return err
+	})
+	if withdraw.Success == 0 && withdraw.Error != "" {
+		// the guard returns rejections of Yobit as errors too
+		return nil, failed(withdraw.Success, withdraw.Error)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := failed(withdraw.Success, withdraw.Error); err != nil {
+		return nil, err
+	}
+	return withdraw.Return, nil
+}
+
+func (s *Server) createYobicode(c *api.Client, r *http.Request) (interface{}, error) {
+	var settings api.CreateYobicodeSettings
+	if err := readBody(r, &settings); err != nil {
+		return nil, err
+	}
+	if settings.Currency == "" || settings.Amount <= 0 {
+		return nil, badRequest("coin_name and a positive amount are required")
+	}
+
+	var create api.CreateYobicode
+	err := s.serial(func() (err error) {
+		create, err = c.Trading().CreateYobicode(&settings)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := failed(create.Success, create.Error); err != nil {
+		return nil, err
+	}
+	return create.Return, nil
+}
+
+// pairsParam returns the pairs of the pair parameters, separated by commas or dashes
+func pairsParam(r *http.Request) []string {
+	var pairs []string
+	for _, v := range r.URL.Query()["pair"] {
+		for _, pair := range strings.FieldsFunc(v, func(c rune) bool { return c == ',' || c == '-' }) {
+			pairs = append(pairs, strings.TrimSpace(pair))
+		}
+	}
+	return pairs
+}
+
+// uintParam returns the numeric query parameter, 0 when it's missing
+func uintParam(r *http.Request, name string) (uint64, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return 0, badRequest(name + " must be a non-negative integer")
+	}
+	return n, nil
+}
+
+// idParam returns the order ID of the path
+func idParam(r *http.Request) (uint64, error) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil || id == 0 {
+		return 0, badRequest("bad order ID " + strconv.Quote(r.PathValue("id")))
+	}
+	return id, nil
+}
+
+// maxBody limits request bodies, they are small JSON objects
+const maxBody = 1 << 16
+
+// readBody decodes the JSON body of the request, unknown fields are rejected
+func readBody(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return badRequest("bad request body: " + err.Error())
+	}
+	return nil
+}
@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 type GetInfo struct {
 	Success uint8      `json:"success"`
 	Return  InfoReturn `json:"return"`
@@ -12,7 +16,7 @@ type InfoReturn struct {
 	Rights           InfoReturnRights   `json:"rights"`            // priviledges of key. withdraw is not used (reserved)
 	TransactionCount int64              `json:"transaction_count"` // always 0 (outdated)
 	OpenOrders       int64              `json:"open_orders"`       // always 0 (outdated)
-	ServerTime       uint64             `json:"server_time"`       // server time
+	ServerTime       time.Time          `json:"server_time"`       // server time
 }
 
 type InfoReturnRights struct {
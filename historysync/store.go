@@ -0,0 +1,191 @@
+package historysync
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+	"time"
+
+	api "github.com/vladivolo/yobit-api"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	tradesBucket = []byte("trades") // nested bucket per pair with records keyed by trade ID
+	stateBucket  = []byte("state")  // last synced trade ID by pair
+)
+
+// Record is a stored trade
+type Record struct {
+	ID uint64 `json:"id"` // trade ID
+	api.THReturn
+}
+
+// Time returns the transaction time of the record
+func (r Record) Time() time.Time {
+	return r.Timestamp
+}
+
+// Store is a local trade history database
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens or creates the database file
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(tradesBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(stateBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Put stores records of the pair and moves its last synced ID forward
+func (s *Store) Put(pair string, records []Record) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(tradesBucket).CreateBucketIfNotExists([]byte(pair))
+		if err != nil {
+			return err
+		}
+
+		state := tx.Bucket(stateBucket)
+		last := decodeID(state.Get([]byte(pair)))
+		for _, r := range records {
+			data, err := json.Marshal(r)
+			if err != nil {
+				return err
+			}
+			err = bucket.Put(encodeID(r.ID), data)
+			if err != nil {
+				return err
+			}
+			if r.ID > last {
+				last = r.ID
+			}
+		}
+
+		return state.Put([]byte(pair), encodeID(last))
+	})
+}
+
+// LastID returns the last synced trade ID of the pair
+func (s *Store) LastID(pair string) (uint64, error) {
+	var last uint64
+	err := s.db.View(func(tx *bolt.Tx) error {
+		last = decodeID(tx.Bucket(stateBucket).Get([]byte(pair)))
+		return nil
+	})
+
+	return last, err
+}
+
+// Pairs returns pairs that have stored records
+func (s *Store) Pairs() ([]string, error) {
+	var pairs []string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(tradesBucket).ForEachBucket(func(name []byte) error {
+			pairs = append(pairs, string(name))
+			return nil
+		})
+	})
+
+	return pairs, err
+}
+
+// Get returns the record with the trade ID of the pair
+func (s *Store) Get(pair string, id uint64) (Record, bool, error) {
+	var record Record
+	var ok bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(tradesBucket).Bucket([]byte(pair))
+		if bucket == nil {
+			return nil
+		}
+		data := bucket.Get(encodeID(id))
+		if data == nil {
+			return nil
+		}
+		ok = true
+		return api.Unmarshal(data, &record)
+	})
+
+	return record, ok, err
+}
+
+// Query returns records of the pair made in [since, until) ordered by trade ID.
+// Zero since or until means no bound, empty pair means all pairs.
+func (s *Store) Query(pair string, since, until time.Time) ([]Record, error) {
+	var records []Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		trades := tx.Bucket(tradesBucket)
+
+		pairs := [][]byte{[]byte(pair)}
+		if pair == "" {
+			pairs = nil
+			trades.ForEachBucket(func(name []byte) error {
+				pairs = append(pairs, name)
+				return nil
+			})
+		}
+
+		for _, name := range pairs {
+			bucket := trades.Bucket(name)
+			if bucket == nil {
+				continue
+			}
+			err := bucket.ForEach(func(k, v []byte) error {
+				var r Record
+				if err := api.Unmarshal(v, &r); err != nil {
+					return err
+				}
+				t := r.Time()
+				if (!since.IsZero() && t.Before(since)) || (!until.IsZero() && !t.Before(until)) {
+					return nil
+				}
+				records = append(records, r)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].ID < records[j].ID
+	})
+
+	return records, err
+}
+
+func encodeID(id uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, id)
+	return b
+}
+
+func decodeID(b []byte) uint64 {
+	if len(b) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b)
+}
@@ -0,0 +1,146 @@
+// Package historysync incrementally copies the trade history of an account into a
+// local embedded database that can be queried by pair and time range.
+package historysync
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// PageSize is the number of trades requested in one TradeHistory call (Yobit maximum is 1000)
+const PageSize = 1000
+
+// History provides the trade history (e.g. *api.TradeAPI)
+type History interface {
+	TradeHistory(t *api.TradeHistorySettings) (api.TradeHistory, error)
+}
+
+// Syncer copies new trades of the pairs into the store
+type Syncer struct {
+	history History
+	store   *Store
+	pairs   []string
+
+	// Errors is called with the sync errors of a pair in Run, if set
+	Errors func(pair string, err error)
+}
+
+// NewSyncer is a constructor for the Syncer
+func NewSyncer(history History, store *Store, pairs []string) *Syncer {
+	return &Syncer{
+		history: history,
+		store:   store,
+		pairs:   pairs,
+	}
+}
+
+// TradedPairs returns active pairs whose base currency is present in the balance.
+// Yobit has no call listing traded pairs, so this is a starting point for Syncer pairs.
+func TradedPairs(info api.Info, balance api.GetInfo) []string {
+	var pairs []string
+	for pair := range info.Pairs {
+		base, _ := api.SplitPair(pair)
+		if _, ok := balance.Return.FundsInclOrders[base]; ok && !info.Hidden(pair) {
+			pairs = append(pairs, pair)
+		}
+	}
+	sort.Strings(pairs)
+
+	return pairs
+}
+
+// Run syncs all pairs every interval until the context is done. A failed pair is
+// reported to Errors and retried on the next interval, the other pairs are synced.
+func (s *Syncer) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, pair := range s.pairs {
+			_, err := s.SyncPair(pair)
+			if err != nil && s.Errors != nil {
+				s.Errors(pair, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync copies new trades of all pairs and returns the number of stored records by pair
+func (s *Syncer) Sync() (map[string]int, error) {
+	synced := make(map[string]int, len(s.pairs))
+	for _, pair := range s.pairs {
+		n, err := s.SyncPair(pair)
+		synced[pair] = n
+		if err != nil {
+			return synced, err
+		}
+	}
+
+	return synced, nil
+}
+
+// SyncPair copies trades of the pair made after the last synced trade ID, page by page
+// in ascending order, and returns the number of stored records
+func (s *Syncer) SyncPair(pair string) (int, error) {
+	last, err := s.store.LastID(pair)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int
+	for {
+		history, err := s.history.TradeHistory(&api.TradeHistorySettings{
+			Pair:   pair,
+			FromID: last + 1,
+			Count:  PageSize,
+			Order:  "ASC",
+		})
+		if err != nil {
+			return total, err
+		}
+		if history.Success == 0 {
+			return total, fmt.Errorf("historysync: %s: %s", pair, history.Error)
+		}
+
+		records := make([]Record, 0, len(history.Return))
+		for key, th := range history.Return {
+			id, err := strconv.ParseUint(key, 10, 64)
+			if err != nil {
+				return total, fmt.Errorf("historysync: %s: bad trade ID %q", pair, key)
+			}
+			if id <= last {
+				continue
+			}
+			records = append(records, Record{ID: id, THReturn: th})
+		}
+		if len(records) == 0 {
+			return total, nil
+		}
+
+		err = s.store.Put(pair, records)
+		if err != nil {
+			return total, err
+		}
+		total += len(records)
+
+		for _, r := range records {
+			if r.ID > last {
+				last = r.ID
+			}
+		}
+		if len(history.Return) < PageSize {
+			return total, nil
+		}
+	}
+}
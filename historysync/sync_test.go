@@ -0,0 +1,81 @@
+package historysync
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// fakeHistory returns count trades of every pair, or the error of the pair
+type fakeHistory struct {
+	count int
+	errs  map[string]error
+}
+
+func (f *fakeHistory) TradeHistory(t *api.TradeHistorySettings) (api.TradeHistory, error) {
+	if err := f.errs[t.Pair]; err != nil {
+		return api.TradeHistory{}, err
+	}
+
+	h := api.NewTradeHistory()
+	h.Success = 1
+	for id := t.FromID; id <= uint64(f.count); id++ {
+		h.Return[strconv.FormatUint(id, 10)] = api.THReturn{Pair: t.Pair, Type: "buy", Amount: 1,
+			Timestamp: time.Unix(int64(id), 0)}
+	}
+	return h, nil
+}
+
+func TestRunContinuesAfterErrors(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	// ltc_btc fails once, doge_btc syncs anyway and ltc_btc is retried
+	history := &fakeHistory{count: 3, errs: map[string]error{"ltc_btc": errors.New("timeout")}}
+	s := NewSyncer(history, store, []string{"ltc_btc", "doge_btc"})
+	var failed []string
+	s.Errors = func(pair string, err error) {
+		failed = append(failed, pair)
+		delete(history.errs, pair)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Run(ctx, time.Millisecond)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		last, err := store.LastID("ltc_btc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if last == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("ltc_btc wasn't synced after the error")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("Run = %v, want context.Canceled", err)
+	}
+
+	if len(failed) != 1 || failed[0] != "ltc_btc" {
+		t.Fatalf("errors of %v, want ltc_btc once", failed)
+	}
+	if last, _ := store.LastID("doge_btc"); last != 3 {
+		t.Fatalf("doge_btc last ID = %d, want 3", last)
+	}
+}
@@ -1,10 +1,12 @@
 package api
 
-import ()
+import (
+	"time"
+)
 
 type Info struct {
 	Success    uint8                             `json:"success"`
-	ServerTime uint64                            `json:"server_time"`
+	ServerTime time.Time                         `json:"server_time"`
 	Pairs      map[string]map[string]interface{} `json:"pairs"`
 	Error      string                            `json:"error"`
 }
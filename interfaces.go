@@ -0,0 +1,34 @@
+package api
+
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -destination mock/mock.go -package mock . MarketData,Trading
+
+// MarketData is a set of public market data operations implemented by PublicAPI
+type MarketData interface {
+	Info() (Info, error)
+	Ticker(t *TickerSettings) (Ticker, error)
+	Depth(t *DepthSettings) (Depth, error)
+	Trades(t *TradesSettings) (Trades, error)
+	OpenInterest(symbol string) (float64, float64, error)
+	OpenInterests(symbols []string) (map[string]TData, error)
+}
+
+// Trading is a set of private trade operations implemented by TradeAPI
+type Trading interface {
+	GetInfo() (GetInfo, error)
+	Trade(t *TradeSettings) (Trade, error)
+	ActiveOrders(t *ActiveOrdersSettings) (ActiveOrders, error)
+	OrderInfo(t *OrderInfoSettings) (OrderInfo, error)
+	CancelOrder(t *CancelOrderSettings) (CancelOrder, error)
+	TradeHistory(t *TradeHistorySettings) (TradeHistory, error)
+	GetDepositAddress(t *GetDepositAddressSettings) (GetDepositAddress, error)
+	WithdrawCoinsToAddress(t *WithdrawCoinsToAddressSettings) (WithdrawCoinsToAddress, error)
+	CreateYobicode(t *CreateYobicodeSettings) (CreateYobicode, error)
+	RedeemYobicode(t *RedeemYobicodeSettings) (RedeemYobicode, error)
+}
+
+var (
+	_ MarketData = (*PublicAPI)(nil)
+	_ Trading    = (*TradeAPI)(nil)
+	_ MarketData = (*Client)(nil)
+	_ Trading    = (*Client)(nil)
+)
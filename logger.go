@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/url"
+	"time"
+)
+
+// Logger is a structured logger taking key-value pairs, *slog.Logger implements it
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// redacted replaces secrets in logs
+const redacted = "[REDACTED]"
+
+// secretParams are request parameters never logged
+var secretParams = []string{"coupon", "address"}
+
+// WithLogger sets the logger of both APIs. Without it the client is silent.
+func WithLogger(l Logger) ClientOption {
+	return func(c *Client) {
+		c.Public.Logger = l
+		c.Private.Logger = l
+	}
+}
+
+// logRequest logs a finished request: errors at Error, HTTP errors at Warn, the rest at Debug.
+// The Key and Sign headers are added after the interceptors and never reach the log.
+func logRequest(l Logger, req *Request, start time.Time, resp *Response, err error) {
+	if l == nil {
+		return
+	}
+
+	size := 0
+	if resp != nil {
+		size = len(resp.Body)
+	}
+	args := []interface{}{
+		"method", req.Method,
+		"endpoint", req.Link,
+		"latency", time.Since(start),
+		"status", statusOf(resp),
+		"size", size,
+		"attempt", req.Attempt,
+		"signed", req.Signed,
+		"params", redactValues(req.Params),
+	}
+
+	switch {
+	case err != nil:
+		l.Error("yobit request failed", append(args, "error", err)...)
+	case statusOf(resp) >= 400:
+		l.Warn("yobit request failed", args...)
+	default:
+		l.Debug("yobit request", args...)
+	}
+}
+
+// redactValues returns the request parameters with secrets replaced
+func redactValues(values url.Values) map[string]string {
+	safe := make(map[string]string, len(values))
+	for k := range values {
+		safe[k] = values.Get(k)
+	}
+	for _, k := range secretParams {
+		if _, ok := safe[k]; ok {
+			safe[k] = redacted
+		}
+	}
+	return safe
+}
@@ -0,0 +1,132 @@
+// Package metrics exposes Prometheus metrics of API usage: request and error
+// counts, latency, rate limiter waits and nonce resyncs. Metrics implements
+// api.Observer and is plugged in with api.WithObserver.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// Error classes of the errors counter
+const (
+	ClassTimeout   = "timeout"   // request timed out or was cancelled
+	ClassTransport = "transport" // connection or read error
+	ClassHTTP      = "http"      // unexpected HTTP status
+	ClassAPI       = "api"       // response with success 0
+	ClassDecode    = "decode"    // response doesn't fit the models
+	ClassOther     = "other"
+)
+
+// Metrics holds the collectors
+type Metrics struct {
+	requests     *prometheus.CounterVec
+	errors       *prometheus.CounterVec
+	latency      *prometheus.HistogramVec
+	wait         *prometheus.HistogramVec
+	nonceResyncs prometheus.Counter
+}
+
+// New is a constructor for the Metrics registered on reg with names prefixed by
+// namespace (on default: yobit)
+func New(reg prometheus.Registerer, namespace string) (*Metrics, error) {
+	if namespace == "" {
+		namespace = "yobit"
+	}
+
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "API requests by method.",
+		}, []string{"method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "errors_total",
+			Help:      "Failed API requests by method and error class.",
+		}, []string{"method", "class"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "API request latency by method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		wait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "ratelimit_wait_seconds",
+			Help:      "Time requests waited for the rate limiter by method.",
+			Buckets:   []float64{0.001, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		}, []string{"method"}),
+		nonceResyncs: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "nonce_resyncs_total",
+			Help:      "Times the nonce was reloaded after another process changed the nonce file.",
+		}),
+	}
+
+	for _, c := range []prometheus.Collector{m.requests, m.errors, m.latency, m.wait, m.nonceResyncs} {
+		err := reg.Register(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// ObserveRequest counts the request and its error and records the latency
+func (m *Metrics) ObserveRequest(method string, latency time.Duration, err error) {
+	m.requests.WithLabelValues(method).Inc()
+	m.latency.WithLabelValues(method).Observe(latency.Seconds())
+	if err != nil {
+		m.errors.WithLabelValues(method, Class(err)).Inc()
+	}
+}
+
+// ObserveWait records the time the request waited for the rate limiter
+func (m *Metrics) ObserveWait(method string, wait time.Duration) {
+	m.wait.WithLabelValues(method).Observe(wait.Seconds())
+}
+
+// ObserveNonceResync counts a nonce resync
+func (m *Metrics) ObserveNonceResync() {
+	m.nonceResyncs.Inc()
+}
+
+// ObserveDecodeError counts a response that failed to decode
+func (m *Metrics) ObserveDecodeError(method string, err error) {
+	m.errors.WithLabelValues(method, ClassDecode).Inc()
+}
+
+// Class returns the error class of the error
+func Class(err error) string {
+	var (
+		httpErr   *api.HTTPError
+		apiErr    *api.APIError
+		decodeErr *api.DecodeError
+		netErr    net.Error
+	)
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return ClassTimeout
+	case errors.As(err, &httpErr):
+		return ClassHTTP
+	case errors.As(err, &apiErr):
+		return ClassAPI
+	case errors.As(err, &decodeErr):
+		return ClassDecode
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return ClassTimeout
+		}
+		return ClassTransport
+	}
+	return ClassOther
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// respond answers every request with the body instead of the API server
+type respond string
+
+func (r respond) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(string(r))),
+		Request:    req,
+	}, nil
+}
+
+func TestObserveDecodeError(t *testing.T) {
+	transport := http.DefaultTransport
+	http.DefaultTransport = respond(`{"ltc_btc":{"high":"abc"}}`)
+	defer func() { http.DefaultTransport = transport }()
+
+	m, err := New(prometheus.NewRegistry(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := api.NewClient("", "", api.WithObserver(m))
+
+	if _, err := client.Ticker(&api.TickerSettings{Pairs: []string{"ltc_btc"}}); err == nil {
+		t.Fatal("Ticker decoded an invalid response")
+	}
+	if n := testutil.ToFloat64(m.errors.WithLabelValues("ticker", ClassDecode)); n != 1 {
+		t.Fatalf("decode errors = %v, want 1", n)
+	}
+}
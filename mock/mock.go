@@ -0,0 +1,306 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/vladivolo/yobit-api (interfaces: MarketData,Trading)
+//
+// Generated by this command:
+//
+//	mockgen -destination mock/mock.go -package mock . MarketData,Trading
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	reflect "reflect"
+
+	api "github.com/vladivolo/yobit-api"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockMarketData is a mock of MarketData interface.
+type MockMarketData struct {
+	ctrl     *gomock.Controller
+	recorder *MockMarketDataMockRecorder
+	isgomock struct{}
+}
+
+// MockMarketDataMockRecorder is the mock recorder for MockMarketData.
+type MockMarketDataMockRecorder struct {
+	mock *MockMarketData
+}
+
+// NewMockMarketData creates a new mock instance.
+func NewMockMarketData(ctrl *gomock.Controller) *MockMarketData {
+	mock := &MockMarketData{ctrl: ctrl}
+	mock.recorder = &MockMarketDataMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMarketData) EXPECT() *MockMarketDataMockRecorder {
+	return m.recorder
+}
+
+// Depth mocks base method.
+func (m *MockMarketData) Depth(t *api.DepthSettings) (api.Depth, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Depth", t)
+	ret0, _ := ret[0].(api.Depth)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Depth indicates an expected call of Depth.
+func (mr *MockMarketDataMockRecorder) Depth(t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Depth", reflect.TypeOf((*MockMarketData)(nil).Depth), t)
+}
+
+// Info mocks base method.
+func (m *MockMarketData) Info() (api.Info, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Info")
+	ret0, _ := ret[0].(api.Info)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Info indicates an expected call of Info.
+func (mr *MockMarketDataMockRecorder) Info() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Info", reflect.TypeOf((*MockMarketData)(nil).Info))
+}
+
+// OpenInterest mocks base method.
+func (m *MockMarketData) OpenInterest(symbol string) (float64, float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenInterest", symbol)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(float64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// OpenInterest indicates an expected call of OpenInterest.
+func (mr *MockMarketDataMockRecorder) OpenInterest(symbol any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenInterest", reflect.TypeOf((*MockMarketData)(nil).OpenInterest), symbol)
+}
+
+// OpenInterests mocks base method.
+func (m *MockMarketData) OpenInterests(symbols []string) (map[string]api.TData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenInterests", symbols)
+	ret0, _ := ret[0].(map[string]api.TData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenInterests indicates an expected call of OpenInterests.
+func (mr *MockMarketDataMockRecorder) OpenInterests(symbols any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenInterests", reflect.TypeOf((*MockMarketData)(nil).OpenInterests), symbols)
+}
+
+// Ticker mocks base method.
+func (m *MockMarketData) Ticker(t *api.TickerSettings) (api.Ticker, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ticker", t)
+	ret0, _ := ret[0].(api.Ticker)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Ticker indicates an expected call of Ticker.
+func (mr *MockMarketDataMockRecorder) Ticker(t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ticker", reflect.TypeOf((*MockMarketData)(nil).Ticker), t)
+}
+
+// Trades mocks base method.
+func (m *MockMarketData) Trades(t *api.TradesSettings) (api.Trades, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trades", t)
+	ret0, _ := ret[0].(api.Trades)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Trades indicates an expected call of Trades.
+func (mr *MockMarketDataMockRecorder) Trades(t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trades", reflect.TypeOf((*MockMarketData)(nil).Trades), t)
+}
+
+// MockTrading is a mock of Trading interface.
+type MockTrading struct {
+	ctrl     *gomock.Controller
+	recorder *MockTradingMockRecorder
+	isgomock struct{}
+}
+
+// MockTradingMockRecorder is the mock recorder for MockTrading.
+type MockTradingMockRecorder struct {
+	mock *MockTrading
+}
+
+// NewMockTrading creates a new mock instance.
+func NewMockTrading(ctrl *gomock.Controller) *MockTrading {
+	mock := &MockTrading{ctrl: ctrl}
+	mock.recorder = &MockTradingMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTrading) EXPECT() *MockTradingMockRecorder {
+	return m.recorder
+}
+
+// ActiveOrders mocks base method.
+func (m *MockTrading) ActiveOrders(t *api.ActiveOrdersSettings) (api.ActiveOrders, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActiveOrders", t)
+	ret0, _ := ret[0].(api.ActiveOrders)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActiveOrders indicates an expected call of ActiveOrders.
+func (mr *MockTradingMockRecorder) ActiveOrders(t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActiveOrders", reflect.TypeOf((*MockTrading)(nil).ActiveOrders), t)
+}
+
+// CancelOrder mocks base method.
+func (m *MockTrading) CancelOrder(t *api.CancelOrderSettings) (api.CancelOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelOrder", t)
+	ret0, _ := ret[0].(api.CancelOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelOrder indicates an expected call of CancelOrder.
+func (mr *MockTradingMockRecorder) CancelOrder(t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelOrder", reflect.TypeOf((*MockTrading)(nil).CancelOrder), t)
+}
+
+// CreateYobicode mocks base method.
+func (m *MockTrading) CreateYobicode(t *api.CreateYobicodeSettings) (api.CreateYobicode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateYobicode", t)
+	ret0, _ := ret[0].(api.CreateYobicode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateYobicode indicates an expected call of CreateYobicode.
+func (mr *MockTradingMockRecorder) CreateYobicode(t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateYobicode", reflect.TypeOf((*MockTrading)(nil).CreateYobicode), t)
+}
+
+// GetDepositAddress mocks base method.
+func (m *MockTrading) GetDepositAddress(t *api.GetDepositAddressSettings) (api.GetDepositAddress, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDepositAddress", t)
+	ret0, _ := ret[0].(api.GetDepositAddress)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDepositAddress indicates an expected call of GetDepositAddress.
+func (mr *MockTradingMockRecorder) GetDepositAddress(t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDepositAddress", reflect.TypeOf((*MockTrading)(nil).GetDepositAddress), t)
+}
+
+// GetInfo mocks base method.
+func (m *MockTrading) GetInfo() (api.GetInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInfo")
+	ret0, _ := ret[0].(api.GetInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInfo indicates an expected call of GetInfo.
+func (mr *MockTradingMockRecorder) GetInfo() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInfo", reflect.TypeOf((*MockTrading)(nil).GetInfo))
+}
+
+// OrderInfo mocks base method.
+func (m *MockTrading) OrderInfo(t *api.OrderInfoSettings) (api.OrderInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OrderInfo", t)
+	ret0, _ := ret[0].(api.OrderInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OrderInfo indicates an expected call of OrderInfo.
+func (mr *MockTradingMockRecorder) OrderInfo(t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrderInfo", reflect.TypeOf((*MockTrading)(nil).OrderInfo), t)
+}
+
+// RedeemYobicode mocks base method.
+func (m *MockTrading) RedeemYobicode(t *api.RedeemYobicodeSettings) (api.RedeemYobicode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeemYobicode", t)
+	ret0, _ := ret[0].(api.RedeemYobicode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeemYobicode indicates an expected call of RedeemYobicode.
+func (mr *MockTradingMockRecorder) RedeemYobicode(t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeemYobicode", reflect.TypeOf((*MockTrading)(nil).RedeemYobicode), t)
+}
+
+// Trade mocks base method.
+func (m *MockTrading) Trade(t *api.TradeSettings) (api.Trade, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trade", t)
+	ret0, _ := ret[0].(api.Trade)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Trade indicates an expected call of Trade.
+func (mr *MockTradingMockRecorder) Trade(t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trade", reflect.TypeOf((*MockTrading)(nil).Trade), t)
+}
+
+// TradeHistory mocks base method.
+func (m *MockTrading) TradeHistory(t *api.TradeHistorySettings) (api.TradeHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TradeHistory", t)
+	ret0, _ := ret[0].(api.TradeHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TradeHistory indicates an expected call of TradeHistory.
+func (mr *MockTradingMockRecorder) TradeHistory(t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TradeHistory", reflect.TypeOf((*MockTrading)(nil).TradeHistory), t)
+}
+
+// WithdrawCoinsToAddress mocks base method.
+func (m *MockTrading) WithdrawCoinsToAddress(t *api.WithdrawCoinsToAddressSettings) (api.WithdrawCoinsToAddress, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithdrawCoinsToAddress", t)
+	ret0, _ := ret[0].(api.WithdrawCoinsToAddress)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WithdrawCoinsToAddress indicates an expected call of WithdrawCoinsToAddress.
+func (mr *MockTradingMockRecorder) WithdrawCoinsToAddress(t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithdrawCoinsToAddress", reflect.TypeOf((*MockTrading)(nil).WithdrawCoinsToAddress), t)
+}
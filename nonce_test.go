@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"os"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestGetNonceConcurrent(t *testing.T) {
+	tests := []struct {
+		name    string
+		virtual bool
+	}{
+		{"nonce file", false},
+		{"virtual nonce", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := "testnoncekey"
+			t.Cleanup(func() { os.Remove("nonce." + key[0:8] + ".txt") })
+			os.Remove("nonce." + key[0:8] + ".txt")
+
+			trade := NewTradeAPI(key, "secret")
+			trade.VirtualNonce = tt.virtual
+			// copies made by WithContext share the nonce of the original
+			apis := []*TradeAPI{trade, trade.WithContext(context.Background()), trade.WithContext(context.TODO())}
+
+			const calls = 50
+			nonces := make(chan int, len(apis)*calls)
+			var wg sync.WaitGroup
+			for _, a := range apis {
+				wg.Add(1)
+				go func(a *TradeAPI) {
+					defer wg.Done()
+					for i := 0; i < calls; i++ {
+						nonce, err := a.GetNonce(key)
+						if err != nil {
+							t.Error(err)
+							return
+						}
+						nonces <- nonce
+					}
+				}(a)
+			}
+			wg.Wait()
+			close(nonces)
+
+			var got []int
+			for n := range nonces {
+				got = append(got, n)
+			}
+			sort.Ints(got)
+			if len(got) != len(apis)*calls {
+				t.Fatalf("got %d nonces, want %d", len(got), len(apis)*calls)
+			}
+			for i, n := range got {
+				if n != i+1 {
+					t.Fatalf("nonces %v are not unique and consecutive", got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Observer is notified about requests of the APIs (example: metrics.Metrics)
+type Observer interface {
+	// ObserveRequest is called after every request. err is the transport error,
+	// an *HTTPError or an *APIError of the response, nil on success.
+	ObserveRequest(method string, latency time.Duration, err error)
+	// ObserveWait is called with the time a request waited for the Limiter
+	ObserveWait(method string, wait time.Duration)
+	// ObserveNonceResync is called when the nonce file was changed by another process
+	ObserveNonceResync()
+	// ObserveDecodeError is called when a response of the method doesn't fit the models
+	ObserveDecodeError(method string, err error)
+}
+
+// Limiter limits the request rate, *rate.Limiter of golang.org/x/time/rate implements it
+type Limiter interface {
+	Wait(ctx context.Context) error
+}
+
+// HTTPError is an unexpected HTTP status of a response
+type HTTPError struct {
+	Status int
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("api: HTTP status %d %s", e.Status, http.StatusText(e.Status))
+}
+
+// APIError is the error message of a response with success 0
+type APIError struct {
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return "api: " + e.Message
+}
+
+// WithObserver sets the observer of both APIs
+func WithObserver(o Observer) ClientOption {
+	return func(c *Client) {
+		c.Public.Observer = o
+		c.Private.Observer = o
+	}
+}
+
+// WithLimiter sets a limiter shared by both APIs
+func WithLimiter(l Limiter) ClientOption {
+	return func(c *Client) {
+		c.Public.Limiter = l
+		c.Private.Limiter = l
+	}
+}
+
+// responseError returns the *HTTPError or *APIError of a response, if any
+func responseError(status int, body []byte) error {
+	if status >= 400 {
+		return &HTTPError{Status: status}
+	}
+	if !bytes.Contains(body, []byte(`"error"`)) {
+		return nil
+	}
+
+	var resp struct {
+		Success json.RawMessage `json:"success"`
+		Error   string          `json:"error"`
+	}
+	if json.Unmarshal(body, &resp) != nil || resp.Error == "" {
+		return nil
+	}
+	if s := string(resp.Success); s == "0" || s == `"0"` {
+		return &APIError{Message: resp.Error}
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package api
 
-import ()
+import (
+	"time"
+)
 
 type ActiveOrdersSettings struct {
 	Pair string `json:"pair"` // pair (example: ltc_btc)
@@ -15,9 +17,20 @@ type CancelOrderSettings struct {
 }
 
 type ActiveOrders struct {
-	Success uint8                             `json:"success"`
-	Return  map[uint64]map[string]interface{} `json:"return"`
-	Error   string                            `json:"error"`
+	Success uint8                `json:"success"`
+	Return  map[uint64]OrderData `json:"return"`
+	Error   string               `json:"error"`
+}
+
+// OrderData is an order of ActiveOrders and OrderInfo
+type OrderData struct {
+	Pair             string    `json:"pair"`              // pair
+	Type             string    `json:"type"`              // transaction type (buy or sell)
+	StartAmount      float64   `json:"start_amount"`      // starting amount at order creation (OrderInfo only)
+	Amount           float64   `json:"amount"`            // order amount remaining to buy or to sell
+	Rate             float64   `json:"rate"`              // price of buying or selling
+	TimestampCreated time.Time `json:"timestamp_created"` // order creation time
+	Status           int       `json:"status"`            // 0 - active, 1 - filled, 2 - cancelled, 3 - cancelled partially filled
 }
 
 type TradeHistorySettings struct {
@@ -33,19 +46,19 @@ type TradeHistorySettings struct {
 
 func NewActiveOrders() ActiveOrders {
 	activeOrders := ActiveOrders{}
-	activeOrders.Return = make(map[uint64]map[string]interface{})
+	activeOrders.Return = make(map[uint64]OrderData)
 	return activeOrders
 }
 
 type OrderInfo struct {
-	Success uint8                             `json:"success"`
-	Return  map[uint64]map[string]interface{} `json:"return"`
-	Error   string                            `json:"error"`
+	Success uint8                `json:"success"`
+	Return  map[uint64]OrderData `json:"return"`
+	Error   string               `json:"error"`
 }
 
 func NewOrderInfo() OrderInfo {
 	orderInfo := OrderInfo{}
-	orderInfo.Return = make(map[uint64]map[string]interface{})
+	orderInfo.Return = make(map[uint64]OrderData)
 	return orderInfo
 }
 
@@ -56,13 +69,13 @@ type CancelOrder struct {
 }
 
 type COData struct {
-	OrderID int                    `json:"order_id"` // order ID
-	Funds   map[string]interface{} `json:"funds"`    // balances active after request
+	OrderID uint64             `json:"order_id"` // order ID
+	Funds   map[string]float64 `json:"funds"`    // balances active after request
 }
 
 func NewCancelOrder() CancelOrder {
 	cancelOrder := CancelOrder{}
-	cancelOrder.Return.Funds = make(map[string]interface{})
+	cancelOrder.Return.Funds = make(map[string]float64)
 	return cancelOrder
 }
 
@@ -73,13 +86,13 @@ type TradeHistory struct {
 }
 
 type THReturn struct {
-	Pair        string  `json:"pair"`          // pair
-	Type        string  `json:"type"`          // transaction type
-	Amount      float64 `json:"amount"`        // amount
-	Rate        float64 `json:"rate"`          // price of buying or selling
-	OrderID     string  `json:"order_id"`      // order ID
-	IsYourOrder byte    `json:"is_your_order"` // is the order yours
-	Timestamp   string  `json:"timestamp"`     // transaction time
+	Pair        string    `json:"pair"`          // pair
+	Type        string    `json:"type"`          // transaction type
+	Amount      float64   `json:"amount"`        // amount
+	Rate        float64   `json:"rate"`          // price of buying or selling
+	OrderID     uint64    `json:"order_id"`      // order ID
+	IsYourOrder byte      `json:"is_your_order"` // is the order yours
+	Timestamp   time.Time `json:"timestamp"`     // transaction time
 }
 
 func NewTradeHistory() TradeHistory {
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"strings"
+)
+
+// SplitPair splits the pair (example: ltc_btc) into base and quote currencies
+func SplitPair(pair string) (string, string) {
+	i := strings.Index(pair, "_")
+	if i < 0 {
+		return pair, ""
+	}
+
+	return pair[:i], pair[i+1:]
+}
+
+// Fee returns the fee of the pair in percent (example: 0.2)
+func (i Info) Fee(pair string) float64 {
+	fee := Float(i.Pairs[pair]["fee"])
+	return fee
+}
+
+// Hidden reports whether the pair is hidden (not traded)
+func (i Info) Hidden(pair string) bool {
+	hidden := Float(i.Pairs[pair]["hidden"])
+	return hidden != 0
+}
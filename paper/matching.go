@@ -0,0 +1,96 @@
+package paper
+
+import (
+	"math"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// Update fills resting orders whose price is crossed by the live order book or by
+// market trades made since the previous update. Fills are made at the order rate.
+func (e *Engine) Update() error {
+	for _, pair := range e.activePairs() {
+		depth, err := e.market.Depth(&api.DepthSettings{Pair: pair})
+		if err != nil {
+			return err
+		}
+		trades, err := e.market.Trades(&api.TradesSettings{Pair: pair})
+		if err != nil {
+			return err
+		}
+
+		e.match(pair, depth.PairData[pair], trades.PairData[pair])
+	}
+
+	return nil
+}
+
+// match fills resting orders of the pair against the book and the trade tape
+func (e *Engine) match(pair string, book api.PData, trades []api.TradeData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// liquidity available to paper orders on each side
+	asks := copyLevels(book.Asks)
+	bids := copyLevels(book.Bids)
+
+	last := e.lastSeen[pair]
+	for _, t := range trades {
+		if t.Timestamp.After(last) {
+			last = t.Timestamp
+		}
+		if !t.Timestamp.After(e.lastSeen[pair]) {
+			continue
+		}
+		// a market sale at the price could have filled resting buys and vice versa
+		if t.Type == "ask" {
+			asks = append(asks, [2]float64{t.Price, t.Amount})
+		} else {
+			bids = append(bids, [2]float64{t.Price, t.Amount})
+		}
+	}
+	e.lastSeen[pair] = last
+
+	for _, o := range e.orders {
+		if o.status != 0 || o.pair != pair {
+			continue
+		}
+
+		levels := asks
+		if o.typ == "sell" {
+			levels = bids
+		}
+		for i := range levels {
+			if o.amount <= 0 {
+				break
+			}
+			if levels[i][1] <= 0 || !o.crosses(levels[i][0]) {
+				continue
+			}
+			amount := math.Min(levels[i][1], o.amount)
+			levels[i][1] -= amount
+			e.fill(o, o.rate, amount)
+		}
+	}
+}
+
+// activePairs returns pairs that have resting orders
+func (e *Engine) activePairs() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var pairs []string
+	for _, o := range e.orders {
+		if o.status == 0 && !seen[o.pair] {
+			seen[o.pair] = true
+			pairs = append(pairs, o.pair)
+		}
+	}
+
+	return pairs
+}
+
+func copyLevels(levels [][2]float64) [][2]float64 {
+	return append([][2]float64(nil), levels...)
+}
@@ -0,0 +1,391 @@
+// Package paper simulates the Yobit Trade API against live public market data.
+// It keeps virtual balances and returns the same response types as api.TradeAPI,
+// so strategy code can be run without risking funds.
+package paper
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// MarketData provides live data used for filling orders (e.g. *api.PublicAPI)
+type MarketData interface {
+	Info() (api.Info, error)
+	Depth(t *api.DepthSettings) (api.Depth, error)
+	Trades(t *api.TradesSettings) (api.Trades, error)
+}
+
+// errNotSupported is returned for operations that move funds outside the exchange
+const errNotSupported = "not supported in paper trading"
+
+var _ api.Trading = (*Engine)(nil)
+
+// order is a resting paper order
+type order struct {
+	id          uint64
+	pair        string
+	typ         string
+	rate        float64
+	startAmount float64
+	amount      float64 // amount not filled yet
+	created     int64
+	status      int // 0 - active, 1 - filled, 2 - cancelled, 3 - cancelled partially filled
+}
+
+// Engine is a paper trading implementation of the trade operations
+type Engine struct {
+	market MarketData
+	info   api.Info
+
+	mu       sync.Mutex
+	funds    map[string]float64 // available balances
+	orders   map[uint64]*order
+	history  map[string]api.THReturn
+	lastSeen map[string]time.Time // last processed market trade time per pair
+	orderID  uint64
+	tradeID  uint64
+
+	// Errors is called with the errors of the updates made by Run, if set
+	Errors func(error)
+}
+
+// New is a constructor for the Engine with the given virtual balances (example: {"btc": 1})
+func New(market MarketData, funds map[string]float64) (*Engine, error) {
+	info, err := market.Info()
+	if err != nil {
+		return nil, err
+	}
+
+	engine := &Engine{
+		market:   market,
+		info:     info,
+		funds:    make(map[string]float64),
+		orders:   make(map[uint64]*order),
+		history:  make(map[string]api.THReturn),
+		lastSeen: make(map[string]time.Time),
+	}
+	for coin, amount := range funds {
+		engine.funds[coin] = amount
+	}
+
+	return engine, nil
+}
+
+// Run fills resting orders against live market data every interval until the context is done.
+// Failed updates are reported to Errors and retried on the next interval.
+func (e *Engine) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			err := e.Update()
+			if err != nil && e.Errors != nil {
+				e.Errors(err)
+			}
+		}
+	}
+}
+
+// GetInfo shows info about virtual balances.
+func (e *Engine) GetInfo() (api.GetInfo, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	balance := api.NewBalance()
+	balance.Success = 1
+	balance.Return.Funds = e.fundsCopy()
+	balance.Return.FundsInclOrders = e.fundsCopy()
+	for _, o := range e.orders {
+		if o.status != 0 {
+			continue
+		}
+		coin, amount := o.locked()
+		balance.Return.FundsInclOrders[coin] += amount
+	}
+	balance.Return.Rights = api.InfoReturnRights{Info: 1, Trade: 1}
+	balance.Return.ServerTime = time.Now()
+
+	return balance, nil
+}
+
+// Trade creates a paper order. The order is matched against the live order book
+// at once and the rest is left resting until Update fills it.
+func (e *Engine) Trade(t *api.TradeSettings) (api.Trade, error) {
+	if t.Type != "buy" && t.Type != "sell" {
+		return api.Trade{Error: "Invalid type"}, nil
+	}
+	if t.Rate <= 0 || t.Amount <= 0 {
+		return api.Trade{Error: "Invalid rate or amount"}, nil
+	}
+	if _, ok := e.info.Pairs[t.Pair]; !ok {
+		return api.Trade{Error: "Invalid pair name: " + t.Pair}, nil
+	}
+
+	depth, err := e.market.Depth(&api.DepthSettings{Pair: t.Pair})
+	if err != nil {
+		return api.Trade{}, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.orderID++
+	o := &order{
+		id:          e.orderID,
+		pair:        t.Pair,
+		typ:         t.Type,
+		rate:        t.Rate,
+		startAmount: t.Amount,
+		amount:      t.Amount,
+		created:     time.Now().Unix(),
+	}
+
+	coin, amount := o.locked()
+	if e.funds[coin] < amount {
+		return api.Trade{Error: fmt.Sprintf("Insufficient funds in %s", coin)}, nil
+	}
+	e.funds[coin] -= amount
+
+	var received float64
+	book := depth.PairData[t.Pair]
+	levels := book.Asks
+	if o.typ == "sell" {
+		levels = book.Bids
+	}
+	for _, level := range levels {
+		if o.amount <= 0 || !o.crosses(level[0]) {
+			break
+		}
+		received += e.fill(o, level[0], math.Min(level[1], o.amount))
+	}
+
+	trade := api.NewTrade()
+	trade.Success = 1
+	trade.Return.Received = received
+	trade.Return.Remains = o.amount
+	if o.amount > 0 {
+		if e.lastSeen[o.pair].IsZero() {
+			e.lastSeen[o.pair] = time.Unix(o.created, 0)
+		}
+		e.orders[o.id] = o
+		trade.Return.OrderID = o.id
+	}
+	trade.Return.Funds = e.fundsCopy()
+
+	return trade, nil
+}
+
+// ActiveOrders returns list of active paper orders of the pair.
+func (e *Engine) ActiveOrders(t *api.ActiveOrdersSettings) (api.ActiveOrders, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	activeOrders := api.NewActiveOrders()
+	activeOrders.Success = 1
+	for id, o := range e.orders {
+		if o.status == 0 && o.pair == t.Pair {
+			data := o.data()
+			data.StartAmount = 0 // not returned by ActiveOrders
+			activeOrders.Return[id] = data
+		}
+	}
+
+	return activeOrders, nil
+}
+
+// OrderInfo returns detailed information about the paper order.
+func (e *Engine) OrderInfo(t *api.OrderInfoSettings) (api.OrderInfo, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	o, ok := e.orders[t.OrderID]
+	if !ok {
+		return api.OrderInfo{Error: "invalid order"}, nil
+	}
+
+	orderInfo := api.NewOrderInfo()
+	orderInfo.Success = 1
+	orderInfo.Return[o.id] = o.data()
+
+	return orderInfo, nil
+}
+
+// CancelOrder cancels the paper order and releases its funds.
+func (e *Engine) CancelOrder(t *api.CancelOrderSettings) (api.CancelOrder, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	o, ok := e.orders[t.OrderID]
+	if !ok || o.status != 0 {
+		return api.CancelOrder{Error: "invalid order"}, nil
+	}
+
+	coin, amount := o.locked()
+	e.funds[coin] += amount
+	if o.amount < o.startAmount {
+		o.status = 3
+	} else {
+		o.status = 2
+	}
+
+	cancelOrder := api.NewCancelOrder()
+	cancelOrder.Success = 1
+	cancelOrder.Return.OrderID = o.id
+	for coin, amount := range e.funds {
+		cancelOrder.Return.Funds[coin] = amount
+	}
+
+	return cancelOrder, nil
+}
+
+// TradeHistory returns history of paper fills of the pair.
+func (e *Engine) TradeHistory(t *api.TradeHistorySettings) (api.TradeHistory, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var ids []uint64
+	for key, th := range e.history {
+		id, _ := strconv.ParseUint(key, 10, 64)
+		ts := uint64(th.Timestamp.Unix())
+		if th.Pair != t.Pair || id < t.FromID || (t.EndID != 0 && id > t.EndID) {
+			continue
+		}
+		if ts < t.Since || (t.End != 0 && ts > t.End) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if t.Order == "ASC" {
+			return ids[i] < ids[j]
+		}
+		return ids[i] > ids[j]
+	})
+
+	count := t.Count
+	if count == 0 {
+		count = 1000
+	}
+	if t.From < uint64(len(ids)) {
+		ids = ids[t.From:]
+	} else {
+		ids = nil
+	}
+	if uint64(len(ids)) > count {
+		ids = ids[:count]
+	}
+
+	tradeHistory := api.NewTradeHistory()
+	tradeHistory.Success = 1
+	for _, id := range ids {
+		key := strconv.FormatUint(id, 10)
+		tradeHistory.Return[key] = e.history[key]
+	}
+
+	return tradeHistory, nil
+}
+
+// GetDepositAddress is not supported in paper trading.
+func (e *Engine) GetDepositAddress(t *api.GetDepositAddressSettings) (api.GetDepositAddress, error) {
+	return api.GetDepositAddress{Error: errNotSupported}, nil
+}
+
+// WithdrawCoinsToAddress is not supported in paper trading.
+func (e *Engine) WithdrawCoinsToAddress(t *api.WithdrawCoinsToAddressSettings) (api.WithdrawCoinsToAddress, error) {
+	return api.WithdrawCoinsToAddress{Error: errNotSupported}, nil
+}
+
+// CreateYobicode is not supported in paper trading.
+func (e *Engine) CreateYobicode(t *api.CreateYobicodeSettings) (api.CreateYobicode, error) {
+	return api.CreateYobicode{Error: errNotSupported}, nil
+}
+
+// RedeemYobicode is not supported in paper trading.
+func (e *Engine) RedeemYobicode(t *api.RedeemYobicodeSettings) (api.RedeemYobicode, error) {
+	return api.RedeemYobicode{Error: errNotSupported}, nil
+}
+
+// fill executes amount of the order at price, credits the received currency minus fee
+// and records the fill in history. It returns the received amount.
+func (e *Engine) fill(o *order, price, amount float64) float64 {
+	base, quote := api.SplitPair(o.pair)
+	fee := e.info.Fee(o.pair) / 100
+
+	var received float64
+	if o.typ == "buy" {
+		received = amount * (1 - fee)
+		e.funds[base] += received
+		e.funds[quote] += amount * (o.rate - price) // refund locked funds above fill price
+	} else {
+		received = amount * price * (1 - fee)
+		e.funds[quote] += received
+	}
+
+	o.amount -= amount
+	if o.amount <= 1e-12 {
+		o.amount = 0
+		o.status = 1
+	}
+
+	e.tradeID++
+	e.history[strconv.FormatUint(e.tradeID, 10)] = api.THReturn{
+		Pair:        o.pair,
+		Type:        o.typ,
+		Amount:      amount,
+		Rate:        price,
+		OrderID:     o.id,
+		IsYourOrder: 1,
+		Timestamp:   time.Now(),
+	}
+
+	return received
+}
+
+func (e *Engine) fundsCopy() map[string]float64 {
+	funds := make(map[string]float64, len(e.funds))
+	for coin, amount := range e.funds {
+		funds[coin] = amount
+	}
+	return funds
+}
+
+// locked returns the currency and amount held by the unfilled part of the order
+func (o *order) locked() (string, float64) {
+	base, quote := api.SplitPair(o.pair)
+	if o.typ == "buy" {
+		return quote, o.amount * o.rate
+	}
+	return base, o.amount
+}
+
+// crosses reports whether the order can be filled at price
+func (o *order) crosses(price float64) bool {
+	if o.typ == "buy" {
+		return price <= o.rate
+	}
+	return price >= o.rate
+}
+
+// data returns the order in the format of OrderInfo
+func (o *order) data() api.OrderData {
+	return api.OrderData{
+		Pair:             o.pair,
+		Type:             o.typ,
+		StartAmount:      o.startAmount,
+		Amount:           o.amount,
+		Rate:             o.rate,
+		TimestampCreated: time.Unix(o.created, 0),
+		Status:           o.status,
+	}
+}
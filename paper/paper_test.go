@@ -0,0 +1,216 @@
+package paper
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	api "github.com/vladivolo/yobit-api"
+	"github.com/vladivolo/yobit-api/mock"
+)
+
+// book is the order book of ltc_btc the orders are filled against
+var book = api.PData{
+	Asks: [][2]float64{{0.01, 10}, {0.011, 10}},
+	Bids: [][2]float64{{0.009, 10}, {0.008, 10}},
+}
+
+// newEngine returns an engine with 1 btc and 100 ltc trading ltc_btc at a fee of
+// 0.2%, the depth answers with the book
+func newEngine(t *testing.T) *Engine {
+	ctrl := gomock.NewController(t)
+	market := mock.NewMockMarketData(ctrl)
+	market.EXPECT().Info().Return(api.Info{Success: 1, Pairs: map[string]map[string]interface{}{
+		"ltc_btc": {"fee": 0.2},
+	}}, nil)
+	market.EXPECT().Depth(&api.DepthSettings{Pair: "ltc_btc"}).Return(api.Depth{
+		Success:  1,
+		PairData: map[string]api.PData{"ltc_btc": book},
+	}, nil).AnyTimes()
+
+	e, err := New(market, map[string]float64{"btc": 1, "ltc": 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+// checkFunds compares the available balances and the balances including orders
+func checkFunds(t *testing.T, e *Engine, funds, inclOrders map[string]float64) {
+	t.Helper()
+
+	info, err := e.GetInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for coin, want := range funds {
+		if got := info.Return.Funds[coin]; math.Abs(got-want) > 1e-9 {
+			t.Errorf("funds of %s = %v, want %v", coin, got, want)
+		}
+	}
+	for coin, want := range inclOrders {
+		if got := info.Return.FundsInclOrders[coin]; math.Abs(got-want) > 1e-9 {
+			t.Errorf("funds of %s including orders = %v, want %v", coin, got, want)
+		}
+	}
+}
+
+func TestTrade(t *testing.T) {
+	tests := []struct {
+		name       string
+		typ        string
+		rate       float64
+		amount     float64
+		err        string
+		received   float64
+		remains    float64
+		funds      map[string]float64
+		inclOrders map[string]float64
+	}{
+		{"buy filled across levels", "buy", 0.011, 15, "", 14.97, 0,
+			map[string]float64{"btc": 0.845, "ltc": 114.97}, map[string]float64{"btc": 0.845, "ltc": 114.97}},
+		{"buy partially filled", "buy", 0.01, 15, "", 9.98, 5,
+			map[string]float64{"btc": 0.85, "ltc": 109.98}, map[string]float64{"btc": 0.9, "ltc": 109.98}},
+		{"buy below the book", "buy", 0.005, 10, "", 0, 10,
+			map[string]float64{"btc": 0.95, "ltc": 100}, map[string]float64{"btc": 1, "ltc": 100}},
+		{"buy filled below its rate", "buy", 0.02, 5, "", 4.99, 0,
+			map[string]float64{"btc": 0.95, "ltc": 104.99}, map[string]float64{"btc": 0.95, "ltc": 104.99}},
+		{"sell partially filled", "sell", 0.009, 15, "", 0.08982, 5,
+			map[string]float64{"btc": 1.08982, "ltc": 85}, map[string]float64{"btc": 1.08982, "ltc": 90}},
+		{"sell filled across levels", "sell", 0.008, 20, "", 0.16966, 0,
+			map[string]float64{"btc": 1.16966, "ltc": 80}, map[string]float64{"btc": 1.16966, "ltc": 80}},
+		{"insufficient funds", "buy", 0.01, 200, "Insufficient funds in btc", 0, 0,
+			map[string]float64{"btc": 1, "ltc": 100}, map[string]float64{"btc": 1, "ltc": 100}},
+		{"invalid type", "short", 0.01, 1, "Invalid type", 0, 0,
+			map[string]float64{"btc": 1, "ltc": 100}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEngine(t)
+
+			trade, err := e.Trade(&api.TradeSettings{Pair: "ltc_btc", Type: tt.typ, Rate: tt.rate, Amount: tt.amount})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if trade.Error != tt.err {
+				t.Fatalf("error = %q, want %q", trade.Error, tt.err)
+			}
+			if math.Abs(trade.Return.Received-tt.received) > 1e-9 || math.Abs(trade.Return.Remains-tt.remains) > 1e-9 {
+				t.Fatalf("received %v remains %v, want %v and %v",
+					trade.Return.Received, trade.Return.Remains, tt.received, tt.remains)
+			}
+			if (trade.Return.OrderID != 0) != (tt.remains > 0) {
+				t.Fatalf("order id = %d with %v remaining", trade.Return.OrderID, tt.remains)
+			}
+			checkFunds(t, e, tt.funds, tt.inclOrders)
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	created := time.Now()
+
+	tests := []struct {
+		name       string
+		book       api.PData
+		trades     []api.TradeData
+		funds      map[string]float64
+		inclOrders map[string]float64
+	}{
+		{"nothing crosses", api.PData{Asks: [][2]float64{{0.006, 10}}}, nil,
+			map[string]float64{"btc": 0.95, "ltc": 100}, map[string]float64{"btc": 1, "ltc": 100}},
+		{"book crosses partially", api.PData{Asks: [][2]float64{{0.004, 4}}}, nil,
+			map[string]float64{"btc": 0.95, "ltc": 103.992}, map[string]float64{"btc": 0.98, "ltc": 103.992}},
+		{"trade crosses", api.PData{},
+			[]api.TradeData{{Type: "ask", Price: 0.005, Amount: 20, Timestamp: created.Add(time.Minute)}},
+			map[string]float64{"btc": 0.95, "ltc": 109.98}, map[string]float64{"btc": 0.95, "ltc": 109.98}},
+		{"trade before the order", api.PData{},
+			[]api.TradeData{{Type: "ask", Price: 0.005, Amount: 20, Timestamp: created.Add(-time.Hour)}},
+			map[string]float64{"btc": 0.95, "ltc": 100}, map[string]float64{"btc": 1, "ltc": 100}},
+		{"buy trade doesn't fill a buy", api.PData{},
+			[]api.TradeData{{Type: "bid", Price: 0.004, Amount: 20, Timestamp: created.Add(time.Minute)}},
+			map[string]float64{"btc": 0.95, "ltc": 100}, map[string]float64{"btc": 1, "ltc": 100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEngine(t)
+
+			// rests below the book
+			trade, err := e.Trade(&api.TradeSettings{Pair: "ltc_btc", Type: "buy", Rate: 0.005, Amount: 10})
+			if err != nil || trade.Return.OrderID == 0 {
+				t.Fatalf("Trade = %+v, %v, want a resting order", trade, err)
+			}
+
+			e.market = newMarket(t, tt.book, tt.trades)
+			if err := e.Update(); err != nil {
+				t.Fatal(err)
+			}
+			checkFunds(t, e, tt.funds, tt.inclOrders)
+		})
+	}
+}
+
+// newMarket returns market data answering Depth and Trades of ltc_btc once
+func newMarket(t *testing.T, book api.PData, trades []api.TradeData) *mock.MockMarketData {
+	market := mock.NewMockMarketData(gomock.NewController(t))
+	market.EXPECT().Depth(&api.DepthSettings{Pair: "ltc_btc"}).Return(api.Depth{
+		Success:  1,
+		PairData: map[string]api.PData{"ltc_btc": book},
+	}, nil)
+	market.EXPECT().Trades(&api.TradesSettings{Pair: "ltc_btc"}).Return(api.Trades{
+		Success:  1,
+		PairData: map[string][]api.TradeData{"ltc_btc": trades},
+	}, nil)
+	return market
+}
+
+func TestCancelOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		typ        string
+		rate       float64
+		amount     float64
+		funds      map[string]float64
+		inclOrders map[string]float64
+	}{
+		{"buy not filled", "buy", 0.005, 10,
+			map[string]float64{"btc": 1, "ltc": 100}, map[string]float64{"btc": 1, "ltc": 100}},
+		{"buy partially filled", "buy", 0.01, 15,
+			map[string]float64{"btc": 0.9, "ltc": 109.98}, map[string]float64{"btc": 0.9, "ltc": 109.98}},
+		{"sell partially filled", "sell", 0.009, 15,
+			map[string]float64{"btc": 1.08982, "ltc": 90}, map[string]float64{"btc": 1.08982, "ltc": 90}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEngine(t)
+
+			trade, err := e.Trade(&api.TradeSettings{Pair: "ltc_btc", Type: tt.typ, Rate: tt.rate, Amount: tt.amount})
+			if err != nil || trade.Return.OrderID == 0 {
+				t.Fatalf("Trade = %+v, %v, want a resting order", trade, err)
+			}
+
+			cancel, err := e.CancelOrder(&api.CancelOrderSettings{OrderID: trade.Return.OrderID})
+			if err != nil || cancel.Success != 1 {
+				t.Fatalf("CancelOrder = %+v, %v", cancel, err)
+			}
+			for coin, want := range tt.funds {
+				if got := cancel.Return.Funds[coin]; math.Abs(got-want) > 1e-9 {
+					t.Errorf("cancel funds of %s = %v, want %v", coin, got, want)
+				}
+			}
+			checkFunds(t, e, tt.funds, tt.inclOrders)
+
+			// the funds are released once
+			cancel, err = e.CancelOrder(&api.CancelOrderSettings{OrderID: trade.Return.OrderID})
+			if err != nil || cancel.Error == "" {
+				t.Fatalf("second CancelOrder = %+v, %v, want an error", cancel, err)
+			}
+			checkFunds(t, e, tt.funds, tt.inclOrders)
+		})
+	}
+}
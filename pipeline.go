@@ -0,0 +1,214 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RequestInfo describes an API request
+type RequestInfo struct {
+	API     string // public or trade
+	Method  string // Trade API method or Public API endpoint (example: Trade, ticker)
+	Pair    string // pair or pairs joined with "-", if any
+	OrderID uint64 // order ID, if any
+	Attempt int    // attempt of the request, starting from 1
+}
+
+// Request is the request descriptor passed through the interceptors
+type Request struct {
+	RequestInfo
+
+	Link   string     // endpoint (example: https://yobit.net/api/3/ticker/ltc_btc)
+	Params url.Values // request parameters, the nonce of signed requests is set by the round trip
+	Signed bool       // signed with the API key and secret (Trade API)
+}
+
+// Response is the HTTP response of a request
+type Response struct {
+	Status int    // HTTP status
+	Body   []byte // response body
+}
+
+var errNoResponse = errors.New("api: no response from the interceptors")
+
+// Handler sends a request
+type Handler func(ctx context.Context, req *Request) (*Response, error)
+
+// Interceptor runs around the rest of the chain: it may change the request before
+// calling next, inspect or replace the response after it, or call next again to
+// retry with Attempt increased. Retried signed requests get a new nonce.
+type Interceptor func(ctx context.Context, req *Request, next Handler) (*Response, error)
+
+// WithInterceptors appends interceptors to the chains of both APIs
+func WithInterceptors(interceptors ...Interceptor) ClientOption {
+	return func(c *Client) {
+		c.Public.Interceptors = append(c.Public.Interceptors, interceptors...)
+		c.Private.Interceptors = append(c.Private.Interceptors, interceptors...)
+	}
+}
+
+// chain wraps the handler with the interceptors, the first one runs first
+func chain(h Handler, interceptors ...Interceptor) Handler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], h
+		h = func(ctx context.Context, req *Request) (*Response, error) {
+			return interceptor(ctx, req, next)
+		}
+	}
+	return h
+}
+
+// hooks are the built-in interceptors configured by the fields of the APIs
+type hooks struct {
+	tracer   Tracer
+	limiter  Limiter
+	logger   Logger
+	observer Observer
+}
+
+// handler returns the request pipeline: user interceptors, tracing, rate limiting,
+// logging and metrics, then the round trip. Tracing runs inside the interceptors,
+// so every attempt of a retried request gets its own span.
+func (h hooks) handler(roundTrip Handler, interceptors []Interceptor) Handler {
+	all := append([]Interceptor(nil), interceptors...)
+	if h.tracer != nil {
+		all = append(all, tracing(h.tracer))
+	}
+	if h.limiter != nil {
+		all = append(all, limiting(h.limiter, h.observer))
+	}
+	if h.logger != nil || h.observer != nil {
+		all = append(all, logging(h.logger, h.observer))
+	}
+
+	return chain(roundTrip, all...)
+}
+
+// tracing starts a span around the request
+func tracing(t Tracer) Interceptor {
+	return func(ctx context.Context, req *Request, next Handler) (*Response, error) {
+		ctx, end := t.StartRequest(ctx, req.RequestInfo)
+		resp, err := next(ctx, req)
+		end(statusOf(resp), errorOf(resp, err))
+		return resp, err
+	}
+}
+
+// limiting waits for the limiter and reports the wait time
+func limiting(l Limiter, o Observer) Interceptor {
+	return func(ctx context.Context, req *Request, next Handler) (*Response, error) {
+		start := time.Now()
+		err := l.Wait(ctx)
+		if o != nil {
+			o.ObserveWait(req.Method, time.Since(start))
+		}
+		if err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+// logging logs the request and reports it to the observer
+func logging(l Logger, o Observer) Interceptor {
+	return func(ctx context.Context, req *Request, next Handler) (*Response, error) {
+		start := time.Now()
+		resp, err := next(ctx, req)
+		logRequest(l, req, start, resp, err)
+		if o != nil {
+			o.ObserveRequest(req.Method, time.Since(start), errorOf(resp, err))
+		}
+		return resp, err
+	}
+}
+
+// roundTrip sends the request over HTTP, signing it with the key and secret if needed
+func roundTrip(ctx context.Context, req *Request, key, secret string) (*Response, error) {
+	requestString := req.Params.Encode()
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", req.Link, strings.NewReader(requestString))
+	if err != nil {
+		return nil, err
+	}
+	if req.Signed {
+		sign := hmac.New(sha512.New, []byte(secret))
+		sign.Write([]byte(requestString))
+
+		httpReq.Header.Add("Key", key)
+		httpReq.Header.Add("Sign", hex.EncodeToString(sign.Sum(nil)))
+		httpReq.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	client := &http.Client{}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return &Response{Status: resp.StatusCode}, err
+	}
+
+	return &Response{Status: resp.StatusCode, Body: body}, nil
+}
+
+// newRequest describes the request to the link with the parameters
+func newRequest(link string, values *url.Values, signed bool) *Request {
+	req := &Request{
+		RequestInfo: RequestInfo{Attempt: 1},
+		Link:        link,
+		Params:      url.Values{},
+		Signed:      signed,
+	}
+	if values != nil {
+		req.Params = *values
+	}
+
+	if strings.HasPrefix(link, PublicApiLink) {
+		req.API = "public"
+		path := strings.TrimPrefix(link, PublicApiLink)
+		if i := strings.Index(path, "/"); i >= 0 {
+			path, req.Pair = path[:i], path[i+1:]
+		}
+		req.Method = path
+	} else {
+		req.API = "trade"
+	}
+
+	if m := req.Params.Get("method"); m != "" {
+		req.Method = m
+	}
+	if p := req.Params.Get("pair"); p != "" {
+		req.Pair = p
+	}
+	req.OrderID, _ = strconv.ParseUint(req.Params.Get("order_id"), 10, 64)
+
+	return req
+}
+
+// statusOf returns the HTTP status of the response, 0 without one
+func statusOf(resp *Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.Status
+}
+
+// errorOf returns the request error or the *HTTPError or *APIError of the response
+func errorOf(resp *Response, err error) error {
+	if err != nil || resp == nil {
+		return err
+	}
+	return responseError(resp.Status, resp.Body)
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+)
+
+// fakeTracer records the attempts of the started spans
+type fakeTracer struct {
+	attempts []int
+}
+
+func (t *fakeTracer) StartRequest(ctx context.Context, info RequestInfo) (context.Context, func(int, error)) {
+	t.attempts = append(t.attempts, info.Attempt)
+	return ctx, func(int, error) {}
+}
+
+func TestPipelineRetry(t *testing.T) {
+	key := "testpipelinekey"
+	t.Cleanup(func() { os.Remove("nonce." + key[0:8] + ".txt") })
+	os.Remove("nonce." + key[0:8] + ".txt")
+
+	var before, after []string // nonces seen by the interceptor around each attempt
+	retry := func(ctx context.Context, req *Request, next Handler) (*Response, error) {
+		for {
+			before = append(before, req.Params.Get("nonce"))
+			resp, err := next(ctx, req)
+			after = append(after, req.Params.Get("nonce"))
+			if err == nil || req.Attempt == 3 {
+				return resp, err
+			}
+			req.Attempt++
+		}
+	}
+
+	tracer := &fakeTracer{}
+	trade := NewTradeAPI(key, "secret")
+	trade.VirtualNonce = true
+	trade.Tracer = tracer
+	trade.Interceptors = []Interceptor{retry}
+
+	// the cancelled context fails every attempt before anything is sent
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := trade.WithContext(ctx).GetInfo(); err == nil {
+		t.Fatal("GetInfo succeeded with a cancelled context")
+	}
+
+	if len(tracer.attempts) != 3 || tracer.attempts[0] != 1 || tracer.attempts[2] != 3 {
+		t.Fatalf("span attempts = %v, want [1 2 3]", tracer.attempts)
+	}
+	if before[0] != "" {
+		t.Fatalf("nonce %s was taken before the interceptor", before[0])
+	}
+	for i, nonce := range after {
+		if n, _ := strconv.Atoi(nonce); n != i+1 {
+			t.Fatalf("nonces = %v, want a new one for every attempt", after)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+// Package pnl calculates realized and unrealized profit and loss from trade history.
+package pnl
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// Method is a lot matching method
+type Method string
+
+const (
+	FIFO    Method = "fifo"    // first bought lots are sold first
+	LIFO    Method = "lifo"    // last bought lots are sold first
+	Average Method = "average" // every sale is matched at the average cost
+)
+
+// Fill is a single executed trade
+type Fill struct {
+	ID     string    // trade ID
+	Pair   string    // pair (example: ltc_btc)
+	Type   string    // buy or sell
+	Amount float64   // amount in base currency
+	Rate   float64   // price in quote currency
+	Time   time.Time // transaction time
+}
+
+// FromHistory converts a TradeHistory response into fills ordered by time
+func FromHistory(h api.TradeHistory) []Fill {
+	fills := make([]Fill, 0, len(h.Return))
+	for id, th := range h.Return {
+		fills = append(fills, Fill{
+			ID:     id,
+			Pair:   th.Pair,
+			Type:   th.Type,
+			Amount: th.Amount,
+			Rate:   th.Rate,
+			Time:   th.Timestamp,
+		})
+	}
+	sortFills(fills)
+
+	return fills
+}
+
+// lot is an open part of a position
+type lot struct {
+	amount float64 // amount in base currency
+	cost   float64 // cost of one base coin in quote currency
+}
+
+// position is the state of a pair
+type position struct {
+	lots      []lot
+	realized  float64
+	fees      float64
+	bought    float64
+	sold      float64
+	unmatched float64
+}
+
+// PairReport is the profit and loss of a pair, in quote currency
+type PairReport struct {
+	Pair       string  // pair
+	Position   float64 // open amount in base currency
+	AvgCost    float64 // average cost of the open amount
+	Price      float64 // price used for unrealized PnL
+	Realized   float64 // realized PnL after fees
+	Unrealized float64 // unrealized PnL of the open amount
+	Fees       float64 // fees paid
+	Bought     float64 // total amount bought in base currency
+	Sold       float64 // total amount sold in base currency
+	Unmatched  float64 // amount sold without a matching buy in the history
+}
+
+// Totals is the aggregate PnL of all pairs with the same quote currency
+type Totals struct {
+	Realized   float64
+	Unrealized float64
+	Fees       float64
+}
+
+// Report is the profit and loss of all pairs
+type Report struct {
+	Method Method
+	Pairs  map[string]PairReport // by pair
+	Totals map[string]Totals     // by quote currency
+}
+
+// Engine matches fills into lots
+type Engine struct {
+	method    Method
+	info      api.Info
+	fills     []Fill // all fills added, matched in time order
+	seen      map[string]bool
+	positions map[string]*position
+	matched   bool // positions are up to date with fills
+}
+
+// New is a constructor for the Engine. Pair fees are taken from info.
+func New(method Method, info api.Info) *Engine {
+	return &Engine{
+		method:    method,
+		info:      info,
+		seen:      make(map[string]bool),
+		positions: make(map[string]*position),
+	}
+}
+
+// AddHistory ingests a TradeHistory response
+func (e *Engine) AddHistory(h api.TradeHistory) {
+	e.Add(FromHistory(h)...)
+}
+
+// Add ingests fills in any order, e.g. pages of history newest first. Fills are
+// matched in time order, then by numeric ID, when a report is made. Fills with an
+// already seen ID are skipped.
+func (e *Engine) Add(fills ...Fill) {
+	for _, f := range fills {
+		if f.ID != "" {
+			if e.seen[f.ID] {
+				continue
+			}
+			e.seen[f.ID] = true
+		}
+		e.fills = append(e.fills, f)
+		e.matched = false
+	}
+}
+
+// match replays all fills in time order into positions
+func (e *Engine) match() {
+	if e.matched {
+		return
+	}
+	sortFills(e.fills)
+
+	e.positions = make(map[string]*position)
+	for _, f := range e.fills {
+		p, ok := e.positions[f.Pair]
+		if !ok {
+			p = &position{}
+			e.positions[f.Pair] = p
+		}
+
+		fee := e.info.Fee(f.Pair) / 100
+		switch f.Type {
+		case "buy":
+			// the fee is taken from the coins received
+			amount := f.Amount * (1 - fee)
+			p.fees += f.Amount * fee * f.Rate
+			p.bought += amount
+			if amount > 0 {
+				p.lots = append(p.lots, lot{amount: amount, cost: f.Amount * f.Rate / amount})
+			}
+		case "sell":
+			p.fees += f.Amount * f.Rate * fee
+			p.sold += f.Amount
+			e.sell(p, f.Amount, f.Rate*(1-fee))
+		}
+	}
+	e.matched = true
+}
+
+// sell matches amount against open lots at the net price
+func (e *Engine) sell(p *position, amount, price float64) {
+	if e.method == Average && len(p.lots) > 1 {
+		var total, cost float64
+		for _, l := range p.lots {
+			total += l.amount
+			cost += l.amount * l.cost
+		}
+		p.lots = []lot{{amount: total, cost: cost / total}}
+	}
+
+	for amount > 1e-12 && len(p.lots) > 0 {
+		i := 0
+		if e.method == LIFO {
+			i = len(p.lots) - 1
+		}
+		l := &p.lots[i]
+
+		matched := amount
+		if l.amount < matched {
+			matched = l.amount
+		}
+		p.realized += matched * (price - l.cost)
+		l.amount -= matched
+		amount -= matched
+
+		if l.amount <= 1e-12 {
+			p.lots = append(p.lots[:i], p.lots[i+1:]...)
+		}
+	}
+	if amount > 1e-12 {
+		p.unmatched += amount
+	}
+}
+
+// Report returns PnL with unrealized PnL at the given prices by pair
+func (e *Engine) Report(prices map[string]float64) Report {
+	e.match()
+
+	report := Report{
+		Method: e.method,
+		Pairs:  make(map[string]PairReport, len(e.positions)),
+		Totals: make(map[string]Totals),
+	}
+
+	for pair, p := range e.positions {
+		pr := PairReport{
+			Pair:      pair,
+			Price:     prices[pair],
+			Realized:  p.realized,
+			Fees:      p.fees,
+			Bought:    p.bought,
+			Sold:      p.sold,
+			Unmatched: p.unmatched,
+		}
+		var cost float64
+		for _, l := range p.lots {
+			pr.Position += l.amount
+			cost += l.amount * l.cost
+		}
+		if pr.Position > 0 {
+			pr.AvgCost = cost / pr.Position
+		}
+		if pr.Price > 0 {
+			// the open amount is valued at the net price a sale would give
+			pr.Unrealized = pr.Position*pr.Price*(1-e.info.Fee(pair)/100) - cost
+		}
+		report.Pairs[pair] = pr
+
+		_, quote := api.SplitPair(pair)
+		t := report.Totals[quote]
+		t.Realized += pr.Realized
+		t.Unrealized += pr.Unrealized
+		t.Fees += pr.Fees
+		report.Totals[quote] = t
+	}
+
+	return report
+}
+
+// Write writes the report as a table of pairs followed by totals by quote currency
+func (r Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+
+	pairs := make([]string, 0, len(r.Pairs))
+	for pair := range r.Pairs {
+		pairs = append(pairs, pair)
+	}
+	sort.Strings(pairs)
+
+	fmt.Fprintln(tw, "pair\tposition\tavg cost\tprice\trealized\tunrealized\tfees\t")
+	for _, pair := range pairs {
+		p := r.Pairs[pair]
+		fmt.Fprintf(tw, "%s\t%.8f\t%.8f\t%.8f\t%.8f\t%.8f\t%.8f\t\n",
+			p.Pair, p.Position, p.AvgCost, p.Price, p.Realized, p.Unrealized, p.Fees)
+	}
+
+	quotes := make([]string, 0, len(r.Totals))
+	for quote := range r.Totals {
+		quotes = append(quotes, quote)
+	}
+	sort.Strings(quotes)
+
+	fmt.Fprintln(tw, "\t\t\t\t\t\t\t")
+	fmt.Fprintln(tw, "total\t\t\t\trealized\tunrealized\tfees\t")
+	for _, quote := range quotes {
+		t := r.Totals[quote]
+		fmt.Fprintf(tw, "%s\t\t\t\t%.8f\t%.8f\t%.8f\t\n", quote, t.Realized, t.Unrealized, t.Fees)
+	}
+
+	return tw.Flush()
+}
+
+// Mark loads last prices of pairs with open positions and returns the report
+func (e *Engine) Mark(market api.MarketData) (Report, error) {
+	e.match()
+
+	var pairs []string
+	for pair, p := range e.positions {
+		if len(p.lots) > 0 {
+			pairs = append(pairs, pair)
+		}
+	}
+	sort.Strings(pairs)
+
+	tickers, err := api.Tickers(market, pairs)
+	if err != nil {
+		return Report{}, err
+	}
+	prices := make(map[string]float64, len(tickers))
+	for pair, data := range tickers {
+		prices[pair] = data.Last
+	}
+
+	return e.Report(prices), nil
+}
+
+// sortFills orders fills by time and then by numeric ID
+func sortFills(fills []Fill) {
+	sort.SliceStable(fills, func(i, j int) bool {
+		if !fills[i].Time.Equal(fills[j].Time) {
+			return fills[i].Time.Before(fills[j].Time)
+		}
+		a, _ := strconv.ParseUint(fills[i].ID, 10, 64)
+		b, _ := strconv.ParseUint(fills[j].ID, 10, 64)
+		return a < b
+	})
+}
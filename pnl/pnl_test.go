@@ -0,0 +1,131 @@
+package pnl
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	api "github.com/vladivolo/yobit-api"
+	"github.com/vladivolo/yobit-api/mock"
+)
+
+func TestAddOutOfOrder(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// newest first, as TradeHistory pages come
+	fills := []Fill{
+		{ID: "4", Pair: "ltc_btc", Type: "sell", Amount: 1, Rate: 30, Time: start.Add(2 * time.Hour)},
+		{ID: "3", Pair: "ltc_btc", Type: "buy", Amount: 1, Rate: 20, Time: start.Add(time.Hour)},
+		{ID: "10", Pair: "ltc_btc", Type: "buy", Amount: 1, Rate: 10, Time: start},
+		{ID: "9", Pair: "ltc_btc", Type: "buy", Amount: 1, Rate: 40, Time: start.Add(2 * time.Hour)},
+	}
+
+	tests := []struct {
+		method   Method
+		realized float64
+		position float64
+		avgCost  float64
+	}{
+		{FIFO, 20, 2, 30},
+		{LIFO, 10, 2, 25},
+		{Average, 15, 2, 27.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			e := New(tt.method, api.Info{Pairs: map[string]map[string]interface{}{"ltc_btc": {"fee": 0}}})
+			// the sell and the buy 9 share a timestamp, the lower ID comes first
+			e.Add(fills[:2]...)
+			e.Add(fills...)
+
+			r := e.Report(nil).Pairs["ltc_btc"]
+			if math.Abs(r.Realized-tt.realized) > 1e-9 || r.Position != tt.position || math.Abs(r.AvgCost-tt.avgCost) > 1e-9 {
+				t.Fatalf("report = %+v, want realized %v position %v avg cost %v", r, tt.realized, tt.position, tt.avgCost)
+			}
+			if r.Unmatched != 0 {
+				t.Fatalf("unmatched = %v, want 0", r.Unmatched)
+			}
+		})
+	}
+}
+
+// feeFills buy ltc at 10 and 20 and sell part of it at 30, paying a fee of 1%
+func feeFills() []Fill {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []Fill{
+		{ID: "1", Pair: "ltc_btc", Type: "buy", Amount: 1, Rate: 10, Time: start},
+		{ID: "2", Pair: "ltc_btc", Type: "buy", Amount: 1, Rate: 20, Time: start.Add(time.Hour)},
+		{ID: "3", Pair: "ltc_btc", Type: "sell", Amount: 1, Rate: 30, Time: start.Add(2 * time.Hour)},
+	}
+}
+
+var feeInfo = api.Info{Pairs: map[string]map[string]interface{}{"ltc_btc": {"fee": 1.0}, "doge_btc": {"fee": 0.0}}}
+
+func TestFees(t *testing.T) {
+	// the buys receive 0.99 ltc each, the sale gives 29.7 btc for 1 ltc
+	tests := []struct {
+		method   Method
+		realized float64
+		avgCost  float64
+	}{
+		{FIFO, 0.99*(29.7-10/0.99) + 0.01*(29.7-20/0.99), 20 / 0.99},
+		{LIFO, 0.99*(29.7-20/0.99) + 0.01*(29.7-10/0.99), 10 / 0.99},
+		{Average, 29.7 - 30/1.98, 30 / 1.98},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			e := New(tt.method, feeInfo)
+			e.Add(feeFills()...)
+
+			r := e.Report(nil).Pairs["ltc_btc"]
+			if math.Abs(r.Realized-tt.realized) > 1e-9 || math.Abs(r.AvgCost-tt.avgCost) > 1e-9 {
+				t.Fatalf("realized %v avg cost %v, want %v and %v", r.Realized, r.AvgCost, tt.realized, tt.avgCost)
+			}
+			if math.Abs(r.Position-0.98) > 1e-9 || math.Abs(r.Bought-1.98) > 1e-9 || r.Sold != 1 {
+				t.Fatalf("position %v bought %v sold %v, want 0.98, 1.98 and 1", r.Position, r.Bought, r.Sold)
+			}
+			if math.Abs(r.Fees-0.6) > 1e-9 {
+				t.Fatalf("fees = %v, want 0.6", r.Fees)
+			}
+		})
+	}
+}
+
+func TestMark(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	market := mock.NewMockMarketData(ctrl)
+	// only pairs with open positions are priced
+	market.EXPECT().Ticker(&api.TickerSettings{Pairs: []string{"ltc_btc"}}).Return(api.Ticker{
+		PairData: map[string]api.TData{"ltc_btc": {Last: 40}},
+	}, nil)
+
+	start := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	e := New(FIFO, feeInfo)
+	e.Add(feeFills()...)
+	e.Add(
+		Fill{ID: "4", Pair: "doge_btc", Type: "buy", Amount: 1, Rate: 1, Time: start},
+		Fill{ID: "5", Pair: "doge_btc", Type: "sell", Amount: 1, Rate: 2, Time: start.Add(time.Hour)},
+	)
+
+	report, err := e.Mark(market)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the open 0.98 ltc at a cost of 20/0.99 is valued at the net price 40*0.99
+	r := report.Pairs["ltc_btc"]
+	unrealized := 0.98*40*0.99 - 0.98*20/0.99
+	if r.Price != 40 || math.Abs(r.Unrealized-unrealized) > 1e-9 {
+		t.Fatalf("price %v unrealized %v, want 40 and %v", r.Price, r.Unrealized, unrealized)
+	}
+	if d := report.Pairs["doge_btc"]; d.Price != 0 || d.Unrealized != 0 || d.Realized != 1 {
+		t.Fatalf("closed pair = %+v, want realized 1 and no price", d)
+	}
+
+	totals := report.Totals["btc"]
+	if math.Abs(totals.Realized-(r.Realized+1)) > 1e-9 || math.Abs(totals.Unrealized-unrealized) > 1e-9 || math.Abs(totals.Fees-0.6) > 1e-9 {
+		t.Fatalf("totals = %+v", totals)
+	}
+}
@@ -0,0 +1,167 @@
+// Package portfolio values account balances in a reference currency.
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	api "github.com/vladivolo/yobit-api"
+	"github.com/vladivolo/yobit-api/convert"
+)
+
+// Balances provides account balances (e.g. *api.TradeAPI)
+type Balances interface {
+	GetInfo() (api.GetInfo, error)
+}
+
+// CoinValue is a valuation of a single coin
+type CoinValue struct {
+	Free        float64  // available balance
+	InOrders    float64  // balance locked in open orders
+	Rate        float64  // price of one coin in the reference currency
+	Value       float64  // value of the whole balance in the reference currency
+	LockedValue float64  // value of the balance locked in open orders
+	Path        []string // pairs of the shortest conversion path, empty for the reference currency itself
+}
+
+// Valuation is a valuation of the whole account
+type Valuation struct {
+	Currency    string               // reference currency
+	Total       float64              // total equity
+	Locked      float64              // equity locked in open orders
+	LockedShare float64              // share of equity locked in open orders (0..1)
+	Coins       map[string]CoinValue // valued coins
+	Unpriced    []string             // coins with balance but without a conversion path or price
+}
+
+// Valuer values balances using live ticker prices
+type Valuer struct {
+	market   api.MarketData
+	balances Balances
+}
+
+// NewValuer is a constructor for the Valuer
+func NewValuer(market api.MarketData, balances Balances) *Valuer {
+	return &Valuer{
+		market:   market,
+		balances: balances,
+	}
+}
+
+// Value returns the account valuation in currency (example: btc, usd, rur)
+func (v *Valuer) Value(currency string) (Valuation, error) {
+	currency = strings.ToLower(currency)
+
+	balance, err := v.balances.GetInfo()
+	if err != nil {
+		return Valuation{}, err
+	}
+	if balance.Success == 0 && balance.Error != "" {
+		return Valuation{}, fmt.Errorf("portfolio: getInfo: %s", balance.Error)
+	}
+
+	info, err := v.market.Info()
+	if err != nil {
+		return Valuation{}, err
+	}
+
+	return v.value(currency, balance.Return, info)
+}
+
+func (v *Valuer) value(currency string, balance api.InfoReturn, info api.Info) (Valuation, error) {
+	valuation := Valuation{
+		Currency: currency,
+		Coins:    make(map[string]CoinValue),
+	}
+
+	total := balance.FundsInclOrders
+	if len(total) == 0 {
+		total = balance.Funds
+	}
+
+	graph := convert.NewGraph(info)
+	paths := make(map[string][]string)
+	var pairs []string
+	seen := make(map[string]bool)
+	for coin, amount := range total {
+		if amount == 0 {
+			continue
+		}
+		var path []string
+		if coin != currency {
+			edges, err := graph.ShortestPath(coin, currency)
+			if err != nil {
+				valuation.Unpriced = append(valuation.Unpriced, coin)
+				continue
+			}
+			for _, e := range edges {
+				path = append(path, e.Pair)
+			}
+		}
+		paths[coin] = path
+		for _, pair := range path {
+			if !seen[pair] {
+				seen[pair] = true
+				pairs = append(pairs, pair)
+			}
+		}
+	}
+
+	tickers, err := api.Tickers(v.market, pairs)
+	if err != nil {
+		return Valuation{}, err
+	}
+
+	for coin, path := range paths {
+		rate, ok := pathRate(coin, path, tickers)
+		if !ok {
+			valuation.Unpriced = append(valuation.Unpriced, coin)
+			continue
+		}
+
+		free := balance.Funds[coin]
+		inOrders := total[coin] - free
+		if inOrders < 0 {
+			inOrders = 0
+		}
+		cv := CoinValue{
+			Free:        free,
+			InOrders:    inOrders,
+			Rate:        rate,
+			Value:       total[coin] * rate,
+			LockedValue: inOrders * rate,
+			Path:        path,
+		}
+		valuation.Coins[coin] = cv
+		valuation.Total += cv.Value
+		valuation.Locked += cv.LockedValue
+	}
+	if valuation.Total > 0 {
+		valuation.LockedShare = valuation.Locked / valuation.Total
+	}
+	sort.Strings(valuation.Unpriced)
+
+	return valuation, nil
+}
+
+// pathRate returns the price of one coin after walking the path at last prices
+func pathRate(coin string, path []string, tickers map[string]api.TData) (float64, bool) {
+	rate := 1.0
+	for _, pair := range path {
+		price := tickers[pair].Last
+		if price <= 0 {
+			return 0, false
+		}
+		base, quote := api.SplitPair(pair)
+		if coin == base {
+			rate *= price
+			coin = quote
+		} else {
+			rate /= price
+			coin = base
+		}
+	}
+
+	return rate, true
+}
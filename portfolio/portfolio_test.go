@@ -0,0 +1,76 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+
+	api "github.com/vladivolo/yobit-api"
+	"github.com/vladivolo/yobit-api/mock"
+)
+
+func TestValue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	market := mock.NewMockMarketData(ctrl)
+	trading := mock.NewMockTrading(ctrl)
+
+	pairs := map[string]map[string]interface{}{
+		"btc_usd":  {"fee": 0.2},
+		"ltc_btc":  {"fee": 0.2},
+		"doge_ltc": {"fee": 0.2},
+		"old_btc":  {"fee": 0.2, "hidden": 1},
+	}
+	last := map[string]float64{"btc_usd": 20000, "ltc_btc": 0.005, "doge_ltc": 0.001}
+	funds := map[string]float64{"usd": 100, "btc": 1, "ltc": 10, "doge": 1000, "old": 5}
+	// 60 more coins priced in btc make the valuation take two Ticker batches
+	for i := 0; i < 60; i++ {
+		coin := fmt.Sprintf("c%02d", i)
+		pairs[coin+"_btc"] = map[string]interface{}{"fee": 0.2}
+		last[coin+"_btc"] = 0.0001
+		funds[coin] = 1
+	}
+
+	market.EXPECT().Info().Return(api.Info{Success: 1, Pairs: pairs}, nil)
+	market.EXPECT().Ticker(gomock.Any()).Times(2).DoAndReturn(func(s *api.TickerSettings) (api.Ticker, error) {
+		if len(s.Pairs) > api.TickerBatch {
+			t.Fatalf("Ticker of %d pairs, want at most %d", len(s.Pairs), api.TickerBatch)
+		}
+		ticker := api.NewTicker()
+		for _, pair := range s.Pairs {
+			ticker.PairData[pair] = api.TData{Last: last[pair]}
+		}
+		return ticker, nil
+	})
+	trading.EXPECT().GetInfo().Return(api.GetInfo{Success: 1, Return: api.InfoReturn{Funds: funds}}, nil)
+
+	valuation, err := NewValuer(market, trading).Value("USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		coin  string
+		value float64
+		path  int
+	}{
+		{"usd", 100, 0},
+		{"btc", 20000, 1},
+		{"ltc", 1000, 2},
+		{"doge", 100, 3},
+		{"c07", 2, 2},
+	}
+	for _, tt := range tests {
+		cv := valuation.Coins[tt.coin]
+		if math.Abs(cv.Value-tt.value) > 1e-6 || len(cv.Path) != tt.path {
+			t.Errorf("%s = %v via %v, want %v in %d hops", tt.coin, cv.Value, cv.Path, tt.value, tt.path)
+		}
+	}
+	if len(valuation.Unpriced) != 1 || valuation.Unpriced[0] != "old" {
+		t.Errorf("unpriced = %v, want [old]", valuation.Unpriced)
+	}
+	if math.Abs(valuation.Total-(21200+120)) > 1e-6 {
+		t.Errorf("total = %v, want %v", valuation.Total, 21200+120)
+	}
+}
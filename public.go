@@ -1,8 +1,8 @@
 package api
 
 import (
-	"encoding/json"
-	"io/ioutil"
+	"context"
+	"errors"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -13,6 +13,16 @@ import (
 type PublicAPI struct {
 	apiKey    string
 	apiSecret string
+
+	Drift    DriftHandler // called for response fields unknown to the models (nil = ignored)
+	Logger   Logger       // logs requests with secrets redacted (nil = silent)
+	Observer Observer     // notified about requests (nil = none)
+	Limiter  Limiter      // waited for before every request (nil = no limit)
+	Tracer   Tracer       // starts a span for every request (nil = no tracing)
+
+	Interceptors []Interceptor // run around every request before tracing, rate limiting and logging
+
+	ctx context.Context // context of the requests, set by WithContext
 }
 
 // NewAPI creates and returns the Public API to the main client.
@@ -24,6 +34,7 @@ func NewPublicAPI(api_key string, api_secret string) *PublicAPI {
 }
 
 // Trades returns information about the last transactions of selected pairs.
+// Error responses of Yobit (example: an invalid pair name) set the Error field.
 func (api *PublicAPI) Trades(t *TradesSettings) (Trades, error) {
 	values, link := api.createLinkTrades(t)
 
@@ -33,7 +44,11 @@ func (api *PublicAPI) Trades(t *TradesSettings) (Trades, error) {
 	}
 
 	trades := NewTrades()
-	err = json.Unmarshal(body, &trades.PairData)
+	trades.Error = publicError(body)
+	if trades.Error != "" {
+		return trades, nil
+	}
+	err = api.decodeResponse("trades", body, &trades.PairData)
 	if err != nil {
 		return Trades{}, err
 	}
@@ -54,7 +69,7 @@ func (api *PublicAPI) Info() (Info, error) {
 		Pairs: map[string]map[string]interface{}{},
 	}
 
-	err = json.Unmarshal(body, &info)
+	err = api.decodeResponse("info", body, &info)
 	if err != nil {
 		return Info{}, err
 	}
@@ -91,6 +106,7 @@ func (api *PublicAPI) OpenInterests(symbols []string) (map[string]TData, error)
 }
 
 // Ticker provides statistic data for the last 24 hours.
+// Error responses of Yobit (example: an invalid pair name) set the Error field.
 func (api *PublicAPI) Ticker(t *TickerSettings) (Ticker, error) {
 	values, link := api.createLinkTicker(t)
 
@@ -100,7 +116,11 @@ func (api *PublicAPI) Ticker(t *TickerSettings) (Ticker, error) {
 	}
 
 	ticker := NewTicker()
-	err = json.Unmarshal(body, &ticker.PairData)
+	ticker.Error = publicError(body)
+	if ticker.Error != "" {
+		return ticker, nil
+	}
+	err = api.decodeResponse("ticker", body, &ticker.PairData)
 	if err != nil {
 		return Ticker{}, err
 	}
@@ -109,6 +129,7 @@ func (api *PublicAPI) Ticker(t *TickerSettings) (Ticker, error) {
 }
 
 // Depth returns information about lists of active orders for selected pairs.
+// Error responses of Yobit (example: an invalid pair name) set the Error field.
 func (api *PublicAPI) Depth(t *DepthSettings) (Depth, error) {
 	values, link := api.createLinkDepth(t)
 
@@ -118,7 +139,11 @@ func (api *PublicAPI) Depth(t *DepthSettings) (Depth, error) {
 	}
 
 	depth := NewDepth()
-	err = json.Unmarshal(body, &depth.PairData)
+	depth.Error = publicError(body)
+	if depth.Error != "" {
+		return depth, nil
+	}
+	err = api.decodeResponse("depth", body, &depth.PairData)
 	if err != nil {
 		return Depth{}, err
 	}
@@ -126,48 +151,44 @@ func (api *PublicAPI) Depth(t *DepthSettings) (Depth, error) {
 	return depth, err
 }
 
-// sendRequest prepares and sends request to server by calling objective functions and returns the body of response
+// sendRequest passes the request through the interceptors and returns the body of response
 func (api *PublicAPI) sendRequest(values *url.Values, link string) ([]byte, error) {
-	req, err := api.prepareRequest(values, link)
-	if err != nil {
-		return []byte{}, err
-	}
+	h := hooks{api.Tracer, api.Limiter, api.Logger, api.Observer}.handler(api.roundTrip, api.Interceptors)
 
-	resp, err := api.sendPost(req)
+	resp, err := h(api.context(), newRequest(link, values, false))
 	if err != nil {
 		return []byte{}, err
 	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return []byte{}, err
+	if resp == nil {
+		return []byte{}, errNoResponse
 	}
 
-	return body, err
+	return resp.Body, nil
 }
 
-// prepareRequest creates link and prepares request to send
-func (api *PublicAPI) prepareRequest(values *url.Values, link string) (*http.Request, error) {
-	requestString := values.Encode()
-
-	req, err := http.NewRequest("POST", link, strings.NewReader(requestString))
-	if err != nil {
-		return nil, err
+// publicError returns the message of an error body, {"success":0,"error":"..."}.
+// Responses with pair data have no success field, so errors don't fit them.
+func publicError(body []byte) string {
+	var apiErr *APIError
+	if errors.As(responseError(http.StatusOK, body), &apiErr) {
+		return apiErr.Message
 	}
-
-	return req, err
+	return ""
 }
 
-// sendPost sends POST request to the API server
-func (api *PublicAPI) sendPost(req *http.Request) (*http.Response, error) {
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+// decodeResponse decodes the response body of the endpoint into v, reporting failures to the Observer
+func (api *PublicAPI) decodeResponse(method string, body []byte, v interface{}) error {
+	err := decode(body, v, api.Drift)
+	if err != nil && api.Observer != nil {
+		api.Observer.ObserveDecodeError(method, err)
 	}
 
-	return resp, err
+	return err
+}
+
+// roundTrip sends the request to the API server
+func (api *PublicAPI) roundTrip(ctx context.Context, req *Request) (*Response, error) {
+	return roundTrip(ctx, req, api.apiKey, api.apiSecret)
 }
 
 func (api *PublicAPI) createLinkInfo() (*url.Values, string) {
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"testing"
+)
+
+// respond answers every request with the body instead of the API server
+func respond(body string) Interceptor {
+	return func(ctx context.Context, req *Request, next Handler) (*Response, error) {
+		return &Response{Status: 200, Body: []byte(body)}, nil
+	}
+}
+
+func TestPublicErrorBody(t *testing.T) {
+	const invalid = `{"success":0,"error":"Invalid pair name: ltc_xxx"}`
+
+	tests := []struct {
+		name string
+		call func(p *PublicAPI) (string, error) // returns the Error field
+	}{
+		{"ticker", func(p *PublicAPI) (string, error) {
+			r, err := p.Ticker(&TickerSettings{Pairs: []string{"ltc_xxx"}})
+			return r.Error, err
+		}},
+		{"depth", func(p *PublicAPI) (string, error) {
+			r, err := p.Depth(&DepthSettings{Pair: "ltc_xxx"})
+			return r.Error, err
+		}},
+		{"trades", func(p *PublicAPI) (string, error) {
+			r, err := p.Trades(&TradesSettings{Pair: "ltc_xxx"})
+			return r.Error, err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			public := NewPublicAPI("", "")
+			public.Interceptors = []Interceptor{respond(invalid)}
+
+			message, err := tt.call(public)
+			if err != nil || message != "Invalid pair name: ltc_xxx" {
+				t.Fatalf("Error = %q, err = %v", message, err)
+			}
+		})
+	}
+}
+
+func TestTickersErrorBody(t *testing.T) {
+	public := NewPublicAPI("", "")
+	public.Interceptors = []Interceptor{respond(`{"success":0,"error":"Invalid pair name: ltc_xxx"}`)}
+
+	_, err := Tickers(public, []string{"ltc_xxx"})
+	if apiErr, ok := err.(*APIError); !ok || apiErr.Message != "Invalid pair name: ltc_xxx" {
+		t.Fatalf("err = %v, want *APIError", err)
+	}
+}
@@ -2,6 +2,9 @@ package api
 
 import ()
 
+// DebugMode has no effect.
+//
+// Deprecated: use WithLogger or the Logger fields of the APIs.
 var DebugMode bool
 
 const (
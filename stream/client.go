@@ -0,0 +1,169 @@
+package stream
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	pingPeriod = 30 * time.Second // time between pings of idle clients
+	pongWait   = 60 * time.Second // time a client has to answer a ping
+	maxRequest = 1024             // maximal size of client messages
+)
+
+// client is a WebSocket connection, its undelivered messages are kept per channel
+type client struct {
+	conn     *websocket.Conn
+	channels map[string]bool // subscribed channels, guarded by Hub.mu
+
+	mu      sync.Mutex
+	pending map[string]Message // latest undelivered message by channel
+	order   []string           // channels of the pending messages in sending order
+	wake    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+func newClient(conn *websocket.Conn) *client {
+	return &client{
+		conn:     conn,
+		channels: make(map[string]bool),
+		pending:  make(map[string]Message),
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+}
+
+// push queues the message, replacing an undelivered one of the same channel
+func (c *client) push(msg Message) {
+	c.mu.Lock()
+	prev, ok := c.pending[msg.Channel]
+	if ok && msg.Type == "error" && prev.Type != "error" {
+		// the data isn't repeated when the polling recovers, keep it
+		c.mu.Unlock()
+		return
+	}
+	if !ok {
+		c.order = append(c.order, msg.Channel)
+	}
+	if prev.Type == "snapshot" && msg.Type == "update" {
+		// the client hasn't got the snapshot yet, the update is the new one
+		msg.Type = "snapshot"
+	}
+	c.pending[msg.Channel] = msg
+	c.mu.Unlock()
+
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// drop forgets the undelivered message of the channel
+func (c *client) drop(channel string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.pending[channel]; !ok {
+		return
+	}
+	delete(c.pending, channel)
+	for i, name := range c.order {
+		if name == channel {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// next returns the oldest undelivered message
+func (c *client) next() (Message, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.order) == 0 {
+		return Message{}, false
+	}
+	name := c.order[0]
+	c.order = c.order[1:]
+	msg := c.pending[name]
+	delete(c.pending, name)
+	return msg, true
+}
+
+// read handles the requests of the client until it disconnects
+func (c *client) read(h *Hub) {
+	c.conn.SetReadLimit(maxRequest)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var req request
+		if err := json.Unmarshal(data, &req); err != nil {
+			c.push(Message{Type: "error", Error: "stream: bad request: " + err.Error()})
+			continue
+		}
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+
+		if _, _, err := ParseChannel(req.Channel); err != nil {
+			c.push(Message{Type: "error", Channel: req.Channel, Error: err.Error()})
+			continue
+		}
+		switch req.Op {
+		case "subscribe":
+			if err := h.subscribe(c, req.Channel); err != nil {
+				c.push(Message{Type: "error", Channel: req.Channel, Error: err.Error()})
+			}
+		case "unsubscribe":
+			h.unsubscribe(c, req.Channel)
+		default:
+			c.push(Message{Type: "error", Channel: req.Channel, Error: "stream: unknown op " + req.Op})
+		}
+	}
+}
+
+// write sends the queued messages and pings, slow clients are disconnected
+func (c *client) write(timeout time.Duration) {
+	ping := time.NewTicker(pingPeriod)
+	defer ping.Stop()
+	defer c.close()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ping.C:
+			if c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(timeout)) != nil {
+				return
+			}
+		case <-c.wake:
+			for {
+				msg, ok := c.next()
+				if !ok {
+					break
+				}
+				c.conn.SetWriteDeadline(time.Now().Add(timeout))
+				if c.conn.WriteJSON(msg) != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// close closes the connection once, which also ends read
+func (c *client) close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
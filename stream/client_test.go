@@ -0,0 +1,81 @@
+package stream
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPush(t *testing.T) {
+	msg := func(typ, channel, data string) Message {
+		m := Message{Type: typ, Channel: channel}
+		if typ == "error" {
+			m.Error = data
+		} else {
+			m.Data = json.RawMessage(data)
+		}
+		return m
+	}
+
+	tests := []struct {
+		name   string
+		pushed []Message
+		want   []Message
+	}{
+		{"updates are replaced by newer ones",
+			[]Message{msg("update", "ticker:a", "1"), msg("update", "ticker:a", "2")},
+			[]Message{msg("update", "ticker:a", "2")}},
+		{"an update of an undelivered snapshot is the snapshot",
+			[]Message{msg("snapshot", "ticker:a", "1"), msg("update", "ticker:a", "2")},
+			[]Message{msg("snapshot", "ticker:a", "2")}},
+		{"an error doesn't replace data",
+			[]Message{msg("update", "ticker:a", "1"), msg("error", "ticker:a", "timeout")},
+			[]Message{msg("update", "ticker:a", "1")}},
+		{"data replaces an error",
+			[]Message{msg("error", "ticker:a", "timeout"), msg("update", "ticker:a", "1")},
+			[]Message{msg("update", "ticker:a", "1")}},
+		{"channels keep the order of their first message",
+			[]Message{msg("update", "ticker:a", "1"), msg("update", "ticker:b", "1"), msg("update", "ticker:a", "2")},
+			[]Message{msg("update", "ticker:a", "2"), msg("update", "ticker:b", "1")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newClient(nil)
+			for _, m := range tt.pushed {
+				c.push(m)
+			}
+
+			var got []Message
+			for {
+				m, ok := c.next()
+				if !ok {
+					break
+				}
+				got = append(got, m)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("messages = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].Type != tt.want[i].Type || got[i].Channel != tt.want[i].Channel ||
+					string(got[i].Data) != string(tt.want[i].Data) || got[i].Error != tt.want[i].Error {
+					t.Fatalf("message %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDrop(t *testing.T) {
+	c := newClient(nil)
+	c.push(Message{Type: "update", Channel: "ticker:a"})
+	c.push(Message{Type: "update", Channel: "ticker:b"})
+	c.drop("ticker:a")
+
+	if m, ok := c.next(); !ok || m.Channel != "ticker:b" {
+		t.Fatalf("next = %+v, want ticker:b", m)
+	}
+	if m, ok := c.next(); ok {
+		t.Fatalf("next = %+v, want nothing", m)
+	}
+}
@@ -0,0 +1,349 @@
+// Package stream polls Ticker, Depth and Trades once for all consumers and pushes
+// the updates to WebSocket subscribers.
+//
+// Clients send {"op": "subscribe", "channel": "ticker:ltc_btc"} or "unsubscribe",
+// channels are ticker:pair, depth:pair and trades:pair of the pairs listed by Info.
+// The server sends
+// {"type": "snapshot"|"update"|"error", "channel": "...", "data": ..., "error": "..."}:
+// the first message of a subscription is the snapshot, or the error while polling
+// the channel fails, then updates follow when the polled data changes. Only pairs
+// with subscribers are polled, tickers in batches of api.TickerBatch pairs.
+//
+// Slow clients don't hold up the others: undelivered messages of a channel are
+// replaced by the newer ones, and clients that don't accept a write in time are
+// disconnected.
+package stream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// Kinds of channels
+const (
+	KindTicker = "ticker"
+	KindDepth  = "depth"
+	KindTrades = "trades"
+)
+
+// Settings of the Hub
+type Settings struct {
+	Interval     time.Duration                   // time between polls (on default: 5s)
+	DepthLimit   uint64                          // size of polled books (on default: API default)
+	TradesLimit  uint64                          // number of polled trades (on default: API default)
+	WriteTimeout time.Duration                   // time a client has to accept a message (on default: 10s)
+	MaxChannels  int                             // channels one client may subscribe to (on default: 100)
+	CheckOrigin  func(r *http.Request) bool      // accepts cross-origin browser clients (nil = same origin only)
+	Errors       func(channel string, err error) // called with polling errors (nil = ignored)
+}
+
+// Message is a message sent to the clients
+type Message struct {
+	Type    string          `json:"type"`            // snapshot, update or error
+	Channel string          `json:"channel"`         // channel, empty for errors of requests
+	Data    json.RawMessage `json:"data,omitempty"`  // TData, PData or []TradeData
+	Error   string          `json:"error,omitempty"` // error text
+}
+
+// request is a message of a client
+type request struct {
+	Op      string `json:"op"`      // subscribe or unsubscribe
+	Channel string `json:"channel"` // channel
+}
+
+// pairsMaxAge is the time the pairs of Info are used before they are reloaded
+const pairsMaxAge = time.Hour
+
+// channel is a polled channel with its subscribers
+type channel struct {
+	last []byte           // last data, the snapshot of new subscribers
+	err  string           // last polling error, sent once and to new subscribers
+	subs map[*client]bool // subscribers, true once they got the snapshot
+}
+
+// Hub polls the market and serves WebSocket clients, an http.Handler
+type Hub struct {
+	market   api.MarketData
+	settings Settings
+	upgrader websocket.Upgrader
+
+	mu       sync.Mutex
+	channels map[string]*channel
+	wake     chan struct{} // polls new channels without waiting for the interval
+
+	pairsMu sync.Mutex
+	pairs   map[string]bool // pairs listed by Info
+	loaded  time.Time       // time the pairs were loaded
+}
+
+// NewHub returns the hub polling the market
+func NewHub(market api.MarketData, settings Settings) *Hub {
+	if settings.Interval <= 0 {
+		settings.Interval = 5 * time.Second
+	}
+	if settings.WriteTimeout <= 0 {
+		settings.WriteTimeout = 10 * time.Second
+	}
+	if settings.MaxChannels <= 0 {
+		settings.MaxChannels = 100
+	}
+
+	return &Hub{
+		market:   market,
+		settings: settings,
+		upgrader: websocket.Upgrader{CheckOrigin: settings.CheckOrigin},
+		channels: make(map[string]*channel),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Run polls the market until the context is done
+func (h *Hub) Run(ctx context.Context) error {
+	ticker := time.NewTicker(h.settings.Interval)
+	defer ticker.Stop()
+
+	for {
+		h.poll()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-h.wake:
+		}
+	}
+}
+
+// ParseChannel returns the kind and the pair of a channel name
+func ParseChannel(name string) (kind, pair string, err error) {
+	kind, pair, ok := strings.Cut(name, ":")
+	if !ok || (kind != KindTicker && kind != KindDepth && kind != KindTrades) {
+		return "", "", fmt.Errorf("stream: unknown channel %q", name)
+	}
+	if pair == "" || strings.Trim(pair, "abcdefghijklmnopqrstuvwxyz0123456789_") != "" {
+		return "", "", fmt.Errorf("stream: bad pair in channel %q", name)
+	}
+	return kind, pair, nil
+}
+
+// poll requests the data of all channels with subscribers
+func (h *Hub) poll() {
+	pairs := h.active()
+
+	if tickers := pairs[KindTicker]; len(tickers) > 0 {
+		t, err := api.Tickers(h.market, tickers)
+		for _, pair := range tickers {
+			data, ok := t[pair]
+			h.publish(KindTicker+":"+pair, data, ok, err)
+		}
+	}
+
+	for _, pair := range pairs[KindDepth] {
+		d, err := h.market.Depth(&api.DepthSettings{Pair: pair, Limit: h.settings.DepthLimit})
+		if err == nil && d.Error != "" {
+			err = &api.APIError{Message: d.Error}
+		}
+		data, ok := d.PairData[pair]
+		h.publish(KindDepth+":"+pair, data, ok, err)
+	}
+
+	for _, pair := range pairs[KindTrades] {
+		t, err := h.market.Trades(&api.TradesSettings{Pair: pair, Limit: h.settings.TradesLimit})
+		if err == nil && t.Error != "" {
+			err = &api.APIError{Message: t.Error}
+		}
+		data, ok := t.PairData[pair]
+		h.publish(KindTrades+":"+pair, data, ok, err)
+	}
+}
+
+// active returns the pairs of the channels with subscribers by kind
+func (h *Hub) active() map[string][]string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	pairs := make(map[string][]string)
+	for name := range h.channels {
+		kind, pair, _ := ParseChannel(name)
+		pairs[kind] = append(pairs[kind], pair)
+	}
+	return pairs
+}
+
+// publish sends the polled data of the channel to the subscribers that haven't seen it
+func (h *Hub) publish(name string, v interface{}, ok bool, err error) {
+	if err == nil && !ok {
+		err = fmt.Errorf("stream: no data for %s", name)
+	}
+	if err != nil {
+		if h.settings.Errors != nil {
+			h.settings.Errors(name, err)
+		}
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if ch := h.channels[name]; ch != nil && ch.err != err.Error() {
+			ch.err = err.Error()
+			for c := range ch.subs {
+				c.push(Message{Type: "error", Channel: name, Error: err.Error()})
+			}
+		}
+		return
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := h.channels[name]
+	if ch == nil {
+		// unsubscribed during the poll
+		return
+	}
+	changed := !bytes.Equal(ch.last, data)
+	ch.last, ch.err = data, ""
+	for c, snapshot := range ch.subs {
+		switch {
+		case !snapshot:
+			c.push(Message{Type: "snapshot", Channel: name, Data: data})
+			ch.subs[c] = true
+		case changed:
+			c.push(Message{Type: "update", Channel: name, Data: data})
+		}
+	}
+}
+
+// checkPair returns an error if Info doesn't list the pair. The pairs are reloaded
+// after pairsMaxAge, the old ones are used while Info fails.
+func (h *Hub) checkPair(pair string) error {
+	h.pairsMu.Lock()
+	defer h.pairsMu.Unlock()
+
+	if h.pairs == nil || time.Since(h.loaded) > pairsMaxAge {
+		info, err := h.market.Info()
+		if err == nil && info.Error != "" {
+			err = &api.APIError{Message: info.Error}
+		}
+		switch {
+		case err == nil:
+			h.pairs = make(map[string]bool, len(info.Pairs))
+			for p := range info.Pairs {
+				h.pairs[p] = true
+			}
+			h.loaded = time.Now()
+		case h.pairs == nil:
+			return fmt.Errorf("stream: cannot load pairs: %v", err)
+		}
+	}
+
+	if !h.pairs[pair] {
+		return fmt.Errorf("stream: unknown pair %q", pair)
+	}
+	return nil
+}
+
+// subscribe adds the client to the channel and sends the snapshot, or the error
+// while polling the channel fails
+func (h *Hub) subscribe(c *client, name string) error {
+	_, pair, err := ParseChannel(name)
+	if err != nil {
+		return err
+	}
+	err = h.checkPair(pair)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if c.channels[name] {
+		return nil
+	}
+	if len(c.channels) >= h.settings.MaxChannels {
+		return fmt.Errorf("stream: no more than %d channels per connection", h.settings.MaxChannels)
+	}
+	ch := h.channels[name]
+	if ch == nil {
+		ch = &channel{subs: make(map[*client]bool)}
+		h.channels[name] = ch
+	}
+	ch.subs[c] = false
+	c.channels[name] = true
+
+	switch {
+	case ch.err != "":
+		// the snapshot follows when the polling recovers
+		c.push(Message{Type: "error", Channel: name, Error: ch.err})
+		return nil
+	case ch.last != nil:
+		c.push(Message{Type: "snapshot", Channel: name, Data: ch.last})
+		ch.subs[c] = true
+		return nil
+	}
+	select {
+	case h.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// unsubscribe removes the client from the channel, channels without subscribers aren't polled
+func (h *Hub) unsubscribe(c *client, name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.remove(c, name)
+}
+
+// remove removes the client from the channel, h.mu is held
+func (h *Hub) remove(c *client, name string) {
+	ch := h.channels[name]
+	if ch == nil {
+		return
+	}
+	delete(ch.subs, c)
+	delete(c.channels, name)
+	c.drop(name)
+	if len(ch.subs) == 0 {
+		delete(h.channels, name)
+	}
+}
+
+// leave removes the client from all channels
+func (h *Hub) leave(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for name := range h.channels {
+		h.remove(c, name)
+	}
+}
+
+// ServeHTTP upgrades the connection and serves the client until it disconnects
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has replied with an error
+		return
+	}
+
+	c := newClient(conn)
+	go c.write(h.settings.WriteTimeout)
+	c.read(h)
+	h.leave(c)
+	c.close()
+}
@@ -0,0 +1,222 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/mock/gomock"
+
+	api "github.com/vladivolo/yobit-api"
+	"github.com/vladivolo/yobit-api/mock"
+)
+
+// pending returns the undelivered message of the channel
+func pending(c *client, channel string) (Message, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	msg, ok := c.pending[channel]
+	return msg, ok
+}
+
+func TestSubscribe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	market := mock.NewMockMarketData(ctrl)
+
+	pairs := map[string]map[string]interface{}{}
+	for i := 0; i < 60; i++ {
+		pairs[fmt.Sprintf("c%02d_btc", i)] = map[string]interface{}{"fee": 0.2}
+	}
+	market.EXPECT().Info().Return(api.Info{Success: 1, Pairs: pairs}, nil)
+	// 60 tickers are polled in two batches
+	market.EXPECT().Ticker(gomock.Any()).Times(2).DoAndReturn(func(s *api.TickerSettings) (api.Ticker, error) {
+		if len(s.Pairs) > api.TickerBatch {
+			t.Fatalf("Ticker of %d pairs, want at most %d", len(s.Pairs), api.TickerBatch)
+		}
+		ticker := api.NewTicker()
+		for _, pair := range s.Pairs {
+			ticker.PairData[pair] = api.TData{Last: 1}
+		}
+		return ticker, nil
+	})
+
+	h := NewHub(market, Settings{MaxChannels: 30})
+	clients := []*client{newClient(nil), newClient(nil)}
+	for i, c := range clients {
+		for j := 0; j < 30; j++ {
+			if err := h.subscribe(c, fmt.Sprintf("ticker:c%02d_btc", i*30+j)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if err := h.subscribe(clients[0], "ticker:c59_btc"); err == nil {
+		t.Fatal("subscribed to more than MaxChannels channels")
+	}
+	if err := h.subscribe(newClient(nil), "ticker:ltc_xxx"); err == nil {
+		t.Fatal("subscribed to a pair missing in Info")
+	}
+
+	h.poll()
+	if msg, ok := pending(clients[1], "ticker:c59_btc"); !ok || msg.Type != "snapshot" {
+		t.Fatalf("message = %+v, want the snapshot", msg)
+	}
+}
+
+func TestSubscribeError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	market := mock.NewMockMarketData(ctrl)
+
+	market.EXPECT().Info().Return(api.Info{Success: 1, Pairs: map[string]map[string]interface{}{"ltc_btc": {}}}, nil)
+	market.EXPECT().Depth(gomock.Any()).Return(api.Depth{}, errors.New("timeout"))
+
+	h := NewHub(market, Settings{})
+	if err := h.subscribe(newClient(nil), "depth:ltc_btc"); err != nil {
+		t.Fatal(err)
+	}
+	h.poll()
+
+	// the new subscriber gets the current error instead of waiting for the data
+	c := newClient(nil)
+	if err := h.subscribe(c, "depth:ltc_btc"); err != nil {
+		t.Fatal(err)
+	}
+	if msg, ok := pending(c, "depth:ltc_btc"); !ok || msg.Type != "error" || msg.Error != "timeout" {
+		t.Fatalf("message = %+v, want the error", msg)
+	}
+}
+
+// serve runs the hub on a test server and returns its WebSocket URL
+func serve(t *testing.T, h *Hub) string {
+	ctx, cancel := context.WithCancel(context.Background())
+	go h.Run(ctx)
+	server := httptest.NewServer(h)
+	t.Cleanup(func() {
+		cancel()
+		server.Close()
+	})
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+// ltcInfo lists ltc_btc only
+var ltcInfo = api.Info{Success: 1, Pairs: map[string]map[string]interface{}{"ltc_btc": {}}}
+
+func TestWebSocket(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	market := mock.NewMockMarketData(ctrl)
+
+	market.EXPECT().Info().Return(ltcInfo, nil)
+	var mu sync.Mutex
+	polls := 0
+	// the price changes once, on the second poll
+	market.EXPECT().Ticker(&api.TickerSettings{Pairs: []string{"ltc_btc"}}).DoAndReturn(func(*api.TickerSettings) (api.Ticker, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		polls++
+		last := 1.0
+		if polls > 1 {
+			last = 2
+		}
+		return api.Ticker{PairData: map[string]api.TData{"ltc_btc": {Last: last}}}, nil
+	}).MinTimes(2)
+
+	url := serve(t, NewHub(market, Settings{Interval: 20 * time.Millisecond}))
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := conn.WriteJSON(request{Op: "subscribe", Channel: "ticker:ltc_xxx"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.WriteJSON(request{Op: "subscribe", Channel: "ticker:ltc_btc"}); err != nil {
+		t.Fatal(err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for i, want := range []struct {
+		typ  string
+		last float64
+	}{{"error", 0}, {"snapshot", 1}, {"update", 2}} {
+		var msg Message
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.Type != want.typ {
+			t.Fatalf("message %d = %+v, want %s", i, msg, want.typ)
+		}
+		if msg.Type == "error" {
+			continue
+		}
+		var data api.TData
+		if err := json.Unmarshal(msg.Data, &data); err != nil || data.Last != want.last {
+			t.Fatalf("message %d data = %s, want last %v", i, msg.Data, want.last)
+		}
+	}
+}
+
+func TestSlowClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	market := mock.NewMockMarketData(ctrl)
+
+	market.EXPECT().Info().Return(ltcInfo, nil)
+	// every poll changes a large book
+	var mu sync.Mutex
+	polls := 0
+	market.EXPECT().Depth(gomock.Any()).DoAndReturn(func(*api.DepthSettings) (api.Depth, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		polls++
+		book := api.PData{Asks: make([][2]float64, 20000)}
+		for i := range book.Asks {
+			book.Asks[i] = [2]float64{float64(polls), float64(i)}
+		}
+		return api.Depth{Success: 1, PairData: map[string]api.PData{"ltc_btc": book}}, nil
+	}).AnyTimes()
+
+	h := NewHub(market, Settings{Interval: 10 * time.Millisecond, WriteTimeout: 50 * time.Millisecond})
+	url := serve(t, h)
+
+	// the client never reads, a small receive buffer fills up fast
+	dialer := websocket.Dialer{NetDial: func(network, addr string) (net.Conn, error) {
+		conn, err := net.Dial(network, addr)
+		if err == nil {
+			conn.(*net.TCPConn).SetReadBuffer(4096)
+		}
+		return conn, err
+	}}
+	conn, _, err := dialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.WriteJSON(request{Op: "subscribe", Channel: "depth:ltc_btc"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the hub drops the client and stops polling its channel
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(20 * time.Millisecond) {
+		h.mu.Lock()
+		subscribed := len(h.channels) > 0
+		h.mu.Unlock()
+		mu.Lock()
+		polled := polls > 0
+		mu.Unlock()
+		if !subscribed && polled {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the slow client wasn't dropped")
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package api
 
-import ()
+import (
+	"time"
+)
 
 type TickerSettings struct {
 	Pairs []string `json:"pair"` // pair (example: ltc_btc-btc_usdt)
@@ -13,15 +15,15 @@ type Ticker struct {
 }
 
 type TData struct {
-	High    float64 `json:"high"`    // maximal price
-	Low     float64 `json:"low"`     // minimal price
-	Avg     float64 `json:"avg"`     // average price
-	Vol     float64 `json:"vol"`     // traded volume
-	VolCur  float64 `json:"vol_cur"` // traded volume in currency
-	Last    float64 `json:"last"`    // last transaction price
-	Buy     float64 `json:"buy"`     // buying price
-	Sell    float64 `json:"sell"`    // selling price
-	Updated int     `json:"updated"` // last cache upgrade
+	High    float64   `json:"high"`    // maximal price
+	Low     float64   `json:"low"`     // minimal price
+	Avg     float64   `json:"avg"`     // average price
+	Vol     float64   `json:"vol"`     // traded volume
+	VolCur  float64   `json:"vol_cur"` // traded volume in currency
+	Last    float64   `json:"last"`    // last transaction price
+	Buy     float64   `json:"buy"`     // buying price
+	Sell    float64   `json:"sell"`    // selling price
+	Updated time.Time `json:"updated"` // last cache upgrade
 }
 
 func NewTicker() Ticker {
@@ -29,3 +31,36 @@ func NewTicker() Ticker {
 	ticker.PairData = make(map[string]TData)
 	return ticker
 }
+
+// TickerBatch is the number of pairs requested in one Ticker call by Tickers
+const TickerBatch = 50
+
+// TickerSource provides tickers (e.g. *PublicAPI, MarketData)
+type TickerSource interface {
+	Ticker(t *TickerSettings) (Ticker, error)
+}
+
+// Tickers requests the tickers of the pairs in batches of TickerBatch and returns
+// the data by pair. Pairs missing in the responses are missing in the result.
+func Tickers(source TickerSource, pairs []string) (map[string]TData, error) {
+	data := make(map[string]TData, len(pairs))
+	for len(pairs) > 0 {
+		n := len(pairs)
+		if n > TickerBatch {
+			n = TickerBatch
+		}
+		ticker, err := source.Ticker(&TickerSettings{Pairs: pairs[:n]})
+		if err != nil {
+			return nil, err
+		}
+		if ticker.Error != "" {
+			return nil, &APIError{Message: ticker.Error}
+		}
+		for pair, d := range ticker.PairData {
+			data[pair] = d
+		}
+		pairs = pairs[n:]
+	}
+
+	return data, nil
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+)
+
+// Tracer starts a span for every request (example: tracing.Tracer). The returned
+// function ends the span with the HTTP status (0 without a response) and the error.
+type Tracer interface {
+	StartRequest(ctx context.Context, info RequestInfo) (context.Context, func(status int, err error))
+}
+
+// WithTracer sets the tracer of both APIs
+func WithTracer(t Tracer) ClientOption {
+	return func(c *Client) {
+		c.Public.Tracer = t
+		c.Private.Tracer = t
+	}
+}
+
+// WithContext returns a copy of the client whose requests use the context for
+// cancellation and tracing
+func (c *Client) WithContext(ctx context.Context) *Client {
+	client := *c
+	client.Public = c.Public.WithContext(ctx)
+	client.Private = c.Private.WithContext(ctx)
+	if c.market == MarketData(c.Public) {
+		client.market = client.Public
+	}
+	if c.trading == Trading(c.Private) {
+		client.trading = client.Private
+	}
+
+	return &client
+}
+
+// WithContext returns a copy of the Public API whose requests use the context
+func (api *PublicAPI) WithContext(ctx context.Context) *PublicAPI {
+	c := *api
+	c.ctx = ctx
+	return &c
+}
+
+// WithContext returns a copy of the Trade API whose requests use the context.
+// The copy shares the nonce of the original.
+func (api *TradeAPI) WithContext(ctx context.Context) *TradeAPI {
+	c := *api
+	c.ctx = ctx
+	if api.parent == nil {
+		c.parent = api
+	}
+	return &c
+}
+
+// context returns the context of the requests
+func (api *PublicAPI) context() context.Context {
+	if api.ctx == nil {
+		return context.Background()
+	}
+	return api.ctx
+}
+
+// context returns the context of the requests
+func (api *TradeAPI) context() context.Context {
+	if api.ctx == nil {
+		return context.Background()
+	}
+	return api.ctx
+}
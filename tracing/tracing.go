@@ -0,0 +1,74 @@
+// Package tracing creates OpenTelemetry spans for API requests. Tracer implements
+// api.Tracer and is plugged in with api.WithTracer; spans are children of the
+// span in the context given to Client.WithContext.
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// Name is the instrumentation name of the tracer
+const Name = "github.com/vladivolo/yobit-api"
+
+// Span attributes
+const (
+	AttrAPI     = attribute.Key("yobit.api")
+	AttrMethod  = attribute.Key("yobit.method")
+	AttrPair    = attribute.Key("yobit.pair")
+	AttrOrderID = attribute.Key("yobit.order_id")
+	AttrAttempt = attribute.Key("yobit.retry.attempt")
+	AttrStatus  = attribute.Key("http.response.status_code")
+)
+
+// Tracer starts spans with a tracer of the provider
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// New is a constructor for the Tracer using the tracer provider
+func New(provider trace.TracerProvider) *Tracer {
+	return &Tracer{
+		tracer: provider.Tracer(Name),
+	}
+}
+
+// StartRequest starts the span of the request
+func (t *Tracer) StartRequest(ctx context.Context, info api.RequestInfo) (context.Context, func(int, error)) {
+	attrs := []attribute.KeyValue{
+		AttrAPI.String(info.API),
+		AttrMethod.String(info.Method),
+		AttrAttempt.Int(info.Attempt),
+	}
+	if info.Pair != "" {
+		attrs = append(attrs, AttrPair.String(info.Pair))
+	}
+	if info.OrderID != 0 {
+		attrs = append(attrs, AttrOrderID.String(strconv.FormatUint(info.OrderID, 10)))
+	}
+
+	ctx, span := t.tracer.Start(ctx, "yobit."+info.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+
+	return ctx, func(status int, err error) {
+		if status != 0 {
+			span.SetAttributes(AttrStatus.Int(status))
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else if status >= http.StatusBadRequest {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,211 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+const (
+	key    = "testtracingkey"
+	secret = "testtracingsecret"
+)
+
+// reply is a response of the test server
+type reply struct {
+	status int
+	body   string
+}
+
+// redirect sends every request to the test server
+type redirect string
+
+func (r redirect) RoundTrip(req *http.Request) (*http.Response, error) {
+	u, err := url.Parse(string(r))
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = u.Scheme, u.Host
+	return transport.RoundTrip(req)
+}
+
+// transport is the transport of the test server
+var transport = http.DefaultTransport
+
+// newClient returns a client whose requests go through the interceptors to a
+// server answering by method, and the recorder of its spans
+func newClient(t *testing.T, replies map[string]reply, interceptors ...api.Interceptor) (*api.Client, *tracetest.SpanRecorder) {
+	t.Cleanup(func() { os.Remove("nonce." + key[0:8] + ".txt") })
+	os.Remove("nonce." + key[0:8] + ".txt")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := r.PostFormValue("method")
+		if method == "" {
+			method = strings.Split(strings.TrimPrefix(r.URL.Path, "/api/3/"), "/")[0]
+		}
+		rp := replies[method]
+		w.WriteHeader(rp.status)
+		w.Write([]byte(rp.body))
+	}))
+	t.Cleanup(server.Close)
+
+	http.DefaultTransport = redirect(server.URL)
+	t.Cleanup(func() { http.DefaultTransport = transport })
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	client := api.NewClient(key, secret,
+		api.WithTracer(New(provider)),
+		api.WithInterceptors(interceptors...),
+	)
+	client.Private.VirtualNonce = true
+
+	return client, recorder
+}
+
+func TestSpans(t *testing.T) {
+	replies := map[string]reply{
+		"ticker":      {200, `{"ltc_btc":{"last":0.01}}`},
+		"depth":       {502, `bad gateway`},
+		"getInfo":     {200, `{"success":0,"error":"invalid nonce"}`},
+		"CancelOrder": {200, `{"success":1,"return":{"order_id":7,"funds":{}}}`},
+	}
+
+	tests := []struct {
+		name   string
+		call   func(c *api.Client) error
+		span   string
+		attrs  map[attribute.Key]string
+		status codes.Code
+	}{
+		{"public",
+			func(c *api.Client) error {
+				_, err := c.Public.Ticker(&api.TickerSettings{Pairs: []string{"ltc_btc"}})
+				return err
+			},
+			"yobit.ticker",
+			map[attribute.Key]string{AttrAPI: "public", AttrMethod: "ticker", AttrPair: "ltc_btc", AttrAttempt: "1", AttrStatus: "200"},
+			codes.Unset},
+		{"http error",
+			func(c *api.Client) error {
+				_, err := c.Public.Depth(&api.DepthSettings{Pair: "ltc_btc"})
+				return err
+			},
+			"yobit.depth",
+			map[attribute.Key]string{AttrAPI: "public", AttrMethod: "depth", AttrPair: "ltc_btc", AttrStatus: "502"},
+			codes.Error},
+		{"api error",
+			func(c *api.Client) error {
+				_, err := c.Private.GetInfo()
+				return err
+			},
+			"yobit.getInfo",
+			map[attribute.Key]string{AttrAPI: "trade", AttrMethod: "getInfo", AttrStatus: "200"},
+			codes.Error},
+		{"order",
+			func(c *api.Client) error {
+				_, err := c.Private.CancelOrder(&api.CancelOrderSettings{OrderID: 7})
+				return err
+			},
+			"yobit.CancelOrder",
+			map[attribute.Key]string{AttrAPI: "trade", AttrMethod: "CancelOrder", AttrOrderID: "7", AttrStatus: "200"},
+			codes.Unset},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, recorder := newClient(t, replies)
+			tt.call(client)
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("%d spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if span.Name() != tt.span {
+				t.Fatalf("span name = %s, want %s", span.Name(), tt.span)
+			}
+			attrs := attributes(span)
+			for k, want := range tt.attrs {
+				if attrs[k] != want {
+					t.Errorf("%s = %q, want %q", k, attrs[k], want)
+				}
+			}
+			if span.Status().Code != tt.status {
+				t.Errorf("status = %v, want %v", span.Status(), tt.status)
+			}
+			if tt.status == codes.Error && tt.attrs[AttrStatus] == "200" && len(span.Events()) == 0 {
+				t.Errorf("the API error wasn't recorded")
+			}
+		})
+	}
+}
+
+func TestRetrySpans(t *testing.T) {
+	retry := func(ctx context.Context, req *api.Request, next api.Handler) (*api.Response, error) {
+		for {
+			resp, err := next(ctx, req)
+			if req.Attempt == 3 {
+				return resp, err
+			}
+			req.Attempt++
+		}
+	}
+	client, recorder := newClient(t, map[string]reply{"getInfo": {503, ""}}, retry)
+	client.Private.GetInfo()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("%d spans, want one per attempt", len(spans))
+	}
+	for i, span := range spans {
+		attrs := attributes(span)
+		if want := string(rune('1' + i)); attrs[AttrAttempt] != want {
+			t.Fatalf("span %d attempt = %s, want %s", i, attrs[AttrAttempt], want)
+		}
+		if span.Status().Code != codes.Error {
+			t.Fatalf("span %d status = %v, want an error", i, span.Status())
+		}
+	}
+}
+
+func TestSignedParamsNotRecorded(t *testing.T) {
+	client, recorder := newClient(t, map[string]reply{"Trade": {200, `{"success":0,"error":"Insufficient funds"}`}})
+	client.Private.Trade(&api.TradeSettings{Pair: "ltc_btc", Type: "buy", Rate: 0.0123, Amount: 456})
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("%d spans, want 1", len(spans))
+	}
+	for k, v := range attributes(spans[0]) {
+		for _, secretValue := range []string{key, secret, "0.0123", "456"} {
+			if strings.Contains(v, secretValue) {
+				t.Errorf("attribute %s = %q records a request parameter", k, v)
+			}
+		}
+		if strings.Contains(string(k), "nonce") || strings.Contains(string(k), "sign") {
+			t.Errorf("attribute %s is recorded", k)
+		}
+	}
+}
+
+// attributes returns the span attributes as strings
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]string {
+	attrs := make(map[attribute.Key]string)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	return attrs
+}
@@ -1,16 +1,11 @@
 package api
 
 import (
-	"crypto/hmac"
-	"crypto/sha512"
-	"encoding/hex"
-	"encoding/json"
-	"fmt"
+	"context"
 	"io/ioutil"
-	"net/http"
 	"net/url"
 	"strconv"
-	"strings"
+	"sync"
 )
 
 // API is the Trade API that included in the main client
@@ -20,6 +15,23 @@ type TradeAPI struct {
 
 	VirtualNonce bool // is for saving to file or not (false = to file by edfault)
 	Nonce        int  // current nonce parameter
+
+	AddressValidator AddressValidator // checks withdrawal addresses before the request is signed (nil = no checks)
+	Drift            DriftHandler     // called for response fields unknown to the models (nil = ignored)
+	Logger           Logger           // logs requests with secrets redacted (nil = silent)
+	Observer         Observer         // notified about requests and nonce resyncs (nil = none)
+	Limiter          Limiter          // waited for before every request (nil = no limit)
+	Tracer           Tracer           // starts a span for every request (nil = no tracing)
+
+	Interceptors []Interceptor // run around every request before tracing, rate limiting and logging
+
+	ctx    context.Context // context of the requests, set by WithContext
+	parent *TradeAPI       // API holding the nonce of copies made by WithContext
+}
+
+// AddressValidator checks the format of a withdrawal address of the coin
+type AddressValidator interface {
+	ValidateAddress(coin, address string) error
 }
 
 // NewAPI creates and returns the Trade API to the main client
@@ -40,7 +52,7 @@ func (api *TradeAPI) GetInfo() (GetInfo, error) {
 	}
 
 	balance := NewBalance()
-	err = json.Unmarshal(body, &balance)
+	err = api.decodeResponse(values.Get("method"), body, &balance)
 	if err != nil {
 		return GetInfo{}, err
 	}
@@ -58,7 +70,7 @@ func (api *TradeAPI) Trade(t *TradeSettings) (Trade, error) {
 	}
 
 	trade := NewTrade()
-	err = json.Unmarshal(body, &trade)
+	err = api.decodeResponse(values.Get("method"), body, &trade)
 	if err != nil {
 		return Trade{}, err
 	}
@@ -76,7 +88,7 @@ func (api *TradeAPI) ActiveOrders(t *ActiveOrdersSettings) (ActiveOrders, error)
 	}
 
 	activeOrders := NewActiveOrders()
-	err = json.Unmarshal(body, &activeOrders)
+	err = api.decodeResponse(values.Get("method"), body, &activeOrders)
 	if err != nil {
 		return ActiveOrders{}, err
 	}
@@ -94,7 +106,7 @@ func (api *TradeAPI) OrderInfo(t *OrderInfoSettings) (OrderInfo, error) {
 	}
 
 	orderInfo := NewOrderInfo()
-	err = json.Unmarshal(body, &orderInfo)
+	err = api.decodeResponse(values.Get("method"), body, &orderInfo)
 	if err != nil {
 		return OrderInfo{}, err
 	}
@@ -112,7 +124,7 @@ func (api *TradeAPI) CancelOrder(t *CancelOrderSettings) (CancelOrder, error) {
 	}
 
 	cancelOrder := NewCancelOrder()
-	err = json.Unmarshal(body, &cancelOrder)
+	err = api.decodeResponse(values.Get("method"), body, &cancelOrder)
 	if err != nil {
 		return CancelOrder{}, err
 	}
@@ -129,10 +141,8 @@ func (api *TradeAPI) TradeHistory(t *TradeHistorySettings) (TradeHistory, error)
 		return TradeHistory{}, err
 	}
 
-	fmt.Println(string(body))
-
 	tradeHistory := NewTradeHistory()
-	err = json.Unmarshal(body, &tradeHistory)
+	err = api.decodeResponse(values.Get("method"), body, &tradeHistory)
 	if err != nil {
 		return TradeHistory{}, err
 	}
@@ -150,7 +160,7 @@ func (api *TradeAPI) GetDepositAddress(t *GetDepositAddressSettings) (GetDeposit
 	}
 
 	getDepositAddress := NewGetDepositAddress()
-	err = json.Unmarshal(body, &getDepositAddress)
+	err = api.decodeResponse(values.Get("method"), body, &getDepositAddress)
 	if err != nil {
 		return GetDepositAddress{}, err
 	}
@@ -160,6 +170,15 @@ func (api *TradeAPI) GetDepositAddress(t *GetDepositAddressSettings) (GetDeposit
 
 // WithdrawCoinsToAddress creates withdrawal request.
 func (api *TradeAPI) WithdrawCoinsToAddress(t *WithdrawCoinsToAddressSettings) (WithdrawCoinsToAddress, error) {
+	if err := t.Validate(); err != nil {
+		return WithdrawCoinsToAddress{}, err
+	}
+	if api.AddressValidator != nil {
+		if err := api.AddressValidator.ValidateAddress(t.CoinName, t.Address); err != nil {
+			return WithdrawCoinsToAddress{}, err
+		}
+	}
+
 	values := api.createLinkWithdrawCoinsToAddress(t)
 
 	body, err := api.sendRequest(values)
@@ -168,7 +187,7 @@ func (api *TradeAPI) WithdrawCoinsToAddress(t *WithdrawCoinsToAddressSettings) (
 	}
 
 	getDepositAddress := NewWithdrawCoinsToAddress()
-	err = json.Unmarshal(body, &getDepositAddress)
+	err = api.decodeResponse(values.Get("method"), body, &getDepositAddress)
 	if err != nil {
 		return WithdrawCoinsToAddress{}, err
 	}
@@ -186,7 +205,7 @@ func (api *TradeAPI) CreateYobicode(t *CreateYobicodeSettings) (CreateYobicode,
 	}
 
 	createYobicode := NewCreateYobicode()
-	err = json.Unmarshal(body, &createYobicode)
+	err = api.decodeResponse(values.Get("method"), body, &createYobicode)
 	if err != nil {
 		return CreateYobicode{}, err
 	}
@@ -204,7 +223,7 @@ func (api *TradeAPI) RedeemYobicode(t *RedeemYobicodeSettings) (RedeemYobicode,
 	}
 
 	redeemYobicode := NewRedeemYobicode()
-	err = json.Unmarshal(body, &redeemYobicode)
+	err = api.decodeResponse(values.Get("method"), body, &redeemYobicode)
 	if err != nil {
 		return RedeemYobicode{}, err
 	}
@@ -213,14 +232,8 @@ func (api *TradeAPI) RedeemYobicode(t *RedeemYobicodeSettings) (RedeemYobicode,
 }
 
 func (api *TradeAPI) createLinkGetInfo() *url.Values {
-	nonce, err := api.GetNonce(api.apiKey)
-	if err != nil {
-		return nil
-	}
-
 	values := url.Values{
 		"method": []string{"getInfo"},
-		"nonce":  []string{strconv.Itoa(nonce)},
 	}
 
 	return &values
@@ -228,14 +241,8 @@ func (api *TradeAPI) createLinkGetInfo() *url.Values {
 }
 
 func (api *TradeAPI) createLinkTrade(th *TradeSettings) *url.Values {
-	nonce, err := api.GetNonce(api.apiKey)
-	if err != nil {
-		return nil
-	}
-
 	values := url.Values{
 		"method": []string{"Trade"},
-		"nonce":  []string{strconv.Itoa(nonce)},
 	}
 
 	if th.Pair != "" {
@@ -258,14 +265,8 @@ func (api *TradeAPI) createLinkTrade(th *TradeSettings) *url.Values {
 }
 
 func (api *TradeAPI) createLinkActiveOrders(th *ActiveOrdersSettings) *url.Values {
-	nonce, err := api.GetNonce(api.apiKey)
-	if err != nil {
-		return nil
-	}
-
 	values := url.Values{
 		"method": []string{"ActiveOrders"},
-		"nonce":  []string{strconv.Itoa(nonce)},
 	}
 
 	if th.Pair != "" {
@@ -279,14 +280,8 @@ func (api *TradeAPI) createLinkActiveOrders(th *ActiveOrdersSettings) *url.Value
 }
 
 func (api *TradeAPI) createLinkOrderInfo(th *OrderInfoSettings) *url.Values {
-	nonce, err := api.GetNonce(api.apiKey)
-	if err != nil {
-		return nil
-	}
-
 	values := url.Values{
 		"method": []string{"OrderInfo"},
-		"nonce":  []string{strconv.Itoa(nonce)},
 	}
 
 	if th.OrderID != 0 {
@@ -300,14 +295,8 @@ func (api *TradeAPI) createLinkOrderInfo(th *OrderInfoSettings) *url.Values {
 }
 
 func (api *TradeAPI) createLinkCancelOrder(th *CancelOrderSettings) *url.Values {
-	nonce, err := api.GetNonce(api.apiKey)
-	if err != nil {
-		return nil
-	}
-
 	values := url.Values{
 		"method": []string{"CancelOrder"},
-		"nonce":  []string{strconv.Itoa(nonce)},
 	}
 
 	if th.OrderID != 0 {
@@ -321,36 +310,30 @@ func (api *TradeAPI) createLinkCancelOrder(th *CancelOrderSettings) *url.Values
 }
 
 func (api *TradeAPI) createLinkTradeHistory(th *TradeHistorySettings) *url.Values {
-	nonce, err := api.GetNonce(api.apiKey)
-	if err != nil {
-		return nil
-	}
-
 	values := url.Values{
 		"method": []string{"TradeHistory"},
-		"nonce":  []string{strconv.Itoa(nonce)},
 	}
 
 	if th.From != 0 {
-		values.Add("From", strconv.FormatUint(th.From, 10))
+		values.Add("from", strconv.FormatUint(th.From, 10))
 	}
 	if th.Count != 0 {
-		values.Add("Count", strconv.FormatUint(th.Count, 10))
+		values.Add("count", strconv.FormatUint(th.Count, 10))
 	}
 	if th.FromID != 0 {
-		values.Add("FromID", strconv.FormatUint(th.FromID, 10))
+		values.Add("from_id", strconv.FormatUint(th.FromID, 10))
 	}
 	if th.EndID != 0 {
-		values.Add("EndID", strconv.FormatUint(th.EndID, 10))
+		values.Add("end_id", strconv.FormatUint(th.EndID, 10))
 	}
 	if th.Order != "" {
-		values.Add("Order", th.Order)
+		values.Add("order", th.Order)
 	}
 	if th.Since != 0 {
-		values.Add("Since", strconv.FormatUint(th.Since, 10))
+		values.Add("since", strconv.FormatUint(th.Since, 10))
 	}
 	if th.End != 0 {
-		values.Add("End", strconv.FormatUint(th.End, 10))
+		values.Add("end", strconv.FormatUint(th.End, 10))
 	}
 	if th.Pair != "" {
 		values.Add("pair", th.Pair)
@@ -363,14 +346,8 @@ func (api *TradeAPI) createLinkTradeHistory(th *TradeHistorySettings) *url.Value
 }
 
 func (api *TradeAPI) createLinkGetDepositAddress(th *GetDepositAddressSettings) *url.Values {
-	nonce, err := api.GetNonce(api.apiKey)
-	if err != nil {
-		return nil
-	}
-
 	values := url.Values{
 		"method": []string{"GetDepositAddress"},
-		"nonce":  []string{strconv.Itoa(nonce)},
 	}
 
 	if th.CoinName != "" {
@@ -387,14 +364,8 @@ func (api *TradeAPI) createLinkGetDepositAddress(th *GetDepositAddressSettings)
 }
 
 func (api *TradeAPI) createLinkWithdrawCoinsToAddress(th *WithdrawCoinsToAddressSettings) *url.Values {
-	nonce, err := api.GetNonce(api.apiKey)
-	if err != nil {
-		return nil
-	}
-
 	values := url.Values{
 		"method": []string{"WithdrawCoinsToAddress"},
-		"nonce":  []string{strconv.Itoa(nonce)},
 	}
 
 	if th.CoinName != "" {
@@ -418,14 +389,8 @@ func (api *TradeAPI) createLinkWithdrawCoinsToAddress(th *WithdrawCoinsToAddress
 }
 
 func (api *TradeAPI) createLinkCreateYobicode(th *CreateYobicodeSettings) *url.Values {
-	nonce, err := api.GetNonce(api.apiKey)
-	if err != nil {
-		return nil
-	}
-
 	values := url.Values{
 		"method": []string{"CreateYobicode"},
-		"nonce":  []string{strconv.Itoa(nonce)},
 	}
 
 	if th.Currency != "" {
@@ -444,14 +409,8 @@ func (api *TradeAPI) createLinkCreateYobicode(th *CreateYobicodeSettings) *url.V
 }
 
 func (api *TradeAPI) createLinkRedeemYobicode(th *RedeemYobicodeSettings) *url.Values {
-	nonce, err := api.GetNonce(api.apiKey)
-	if err != nil {
-		return nil
-	}
-
 	values := url.Values{
 		"method": []string{"RedeemYobicode"},
-		"nonce":  []string{strconv.Itoa(nonce)},
 	}
 
 	if th.Coupon != "" {
@@ -464,75 +423,79 @@ func (api *TradeAPI) createLinkRedeemYobicode(th *RedeemYobicodeSettings) *url.V
 
 }
 
-// sendRequest prepares and sends request to server by calling objective functions and returns the body of response
+// sendRequest passes the request through the interceptors and returns the body of response
 func (api *TradeAPI) sendRequest(values *url.Values) ([]byte, error) {
-	req, err := api.prepareRequest(values)
-	if err != nil {
-		return []byte{}, err
-	}
+	h := hooks{api.Tracer, api.Limiter, api.Logger, api.Observer}.handler(api.roundTrip, api.Interceptors)
 
-	resp, err := api.sendPost(req)
+	resp, err := h(api.context(), newRequest(TradeApiLink, values, true))
 	if err != nil {
 		return []byte{}, err
 	}
-
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return []byte{}, err
+	if resp == nil {
+		return []byte{}, errNoResponse
 	}
 
-	return body, err
+	return resp.Body, nil
 }
 
-// prepareRequest creates link and prepares request to send
-func (api *TradeAPI) prepareRequest(values *url.Values) (*http.Request, error) {
-	requestString := values.Encode()
-
-	sign := hmac.New(sha512.New, []byte(api.apiSecret))
-	sign.Write([]byte(requestString))
-
-	req, err := http.NewRequest("POST", TradeApiLink, strings.NewReader(requestString))
-	if err != nil {
-		return nil, err
+// decodeResponse decodes the response body of the method into v, reporting failures to the Observer
+func (api *TradeAPI) decodeResponse(method string, body []byte, v interface{}) error {
+	err := decode(body, v, api.Drift)
+	if err != nil && api.Observer != nil {
+		api.Observer.ObserveDecodeError(method, err)
 	}
 
-	req.Header.Add("Key", api.apiKey)
-	req.Header.Add("Sign", hex.EncodeToString(sign.Sum(nil)))
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-
-	return req, err
+	return err
 }
 
-// sendPost sends POST request to the TradeAPI server
-func (api *TradeAPI) sendPost(req *http.Request) (*http.Response, error) {
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// roundTrip signs and sends the request to the TradeAPI server. The nonce is taken
+// here, after the interceptors and the limiter, so requests reach the server in
+// nonce order and every retry gets a new one.
+func (api *TradeAPI) roundTrip(ctx context.Context, req *Request) (*Response, error) {
+	nonce, err := api.GetNonce(api.apiKey)
 	if err != nil {
 		return nil, err
 	}
+	req.Params.Set("nonce", strconv.Itoa(nonce))
 
-	return resp, err
+	return roundTrip(ctx, req, api.apiKey, api.apiSecret)
 }
 
+// nonceMu guards the nonce counters and files, copies made by WithContext and
+// APIs with the same key share them
+var nonceMu sync.Mutex
+
 // GetNonce is a maintenance function for getting and storing nonce counter
 func (api *TradeAPI) GetNonce(Key string) (int, error) {
+	nonceMu.Lock()
+	defer nonceMu.Unlock()
+
+	n := api
+	if api.parent != nil {
+		n = api.parent
+	}
+
 	nonceFileName := "nonce." + Key[0:8] + ".txt"
-	if api.VirtualNonce != true {
+	if n.VirtualNonce != true {
 		nonceBytes, err := ioutil.ReadFile(nonceFileName)
 		if err == nil {
-			api.Nonce, _ = strconv.Atoi(string(nonceBytes))
+			nonce, _ := strconv.Atoi(string(nonceBytes))
+			if n.Nonce != 0 && nonce != n.Nonce && api.Observer != nil {
+				api.Observer.ObserveNonceResync()
+			}
+			n.Nonce = nonce
 		}
 	}
-	api.Nonce++
-	err := api.WriteNonce(api.Nonce, nonceFileName)
+	n.Nonce++
+	err := api.WriteNonce(n.Nonce, nonceFileName)
 	if err != nil {
-		fmt.Println(err)
+		if api.Logger != nil {
+			api.Logger.Error("yobit nonce write failed", "file", nonceFileName, "error", err)
+		}
 		return 0, err
 	}
 
-	return api.Nonce, err
+	return n.Nonce, err
 }
 
 // WriteNonce writes nonce to the file
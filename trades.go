@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 type Trade struct {
 	Success uint8       `json:"success"`
 	Return  TradeReturn `json:"return"`
@@ -9,7 +13,7 @@ type Trade struct {
 type TradeReturn struct {
 	Received float64            `json:"received"` // amount of currency bought / sold
 	Remains  float64            `json:"remains"`  // amount of currency to buy / to sell
-	OrderID  int                `json:"order_id"` // created order ID
+	OrderID  uint64             `json:"order_id"` // created order ID
 	Funds    map[string]float64 `json:"funds"`    // funds active after request
 }
 
@@ -28,11 +32,11 @@ type Trades struct {
 }
 
 type TradeData struct {
-	Type      string  `json:"type"`      // ask - sell, bid - buy
-	Price     float64 `json:"price"`     // buying / selling price
-	Amount    float64 `json:"amount"`    // amount
-	Tid       uint    `json:"tid"`       // transaction id
-	Timestamp int64   `json:"timestamp"` // transaction timestamp
+	Type      string    `json:"type"`      // ask - sell, bid - buy
+	Price     float64   `json:"price"`     // buying / selling price
+	Amount    float64   `json:"amount"`    // amount
+	Tid       uint64    `json:"tid"`       // transaction id
+	Timestamp time.Time `json:"timestamp"` // transaction time
 }
 
 type TradeSettings struct {
@@ -68,11 +72,11 @@ func Separate(t Trades, pair string) ([]TradeData, []TradeData) {
 	return asks, bids
 }
 
-// GetPriceBefore returns first action price before specified timestamp
-func GetPriceBefore(tds []TradeData, before int64) (price float64) {
-	var currentTimeVal int64
+// GetPriceBefore returns first action price before specified time
+func GetPriceBefore(tds []TradeData, before time.Time) (price float64) {
+	var currentTimeVal time.Time
 	for _, val := range tds {
-		if val.Timestamp < before && val.Timestamp > currentTimeVal {
+		if val.Timestamp.Before(before) && val.Timestamp.After(currentTimeVal) {
 			currentTimeVal = val.Timestamp
 			price = val.Price
 		}
@@ -1,6 +1,9 @@
 package api
 
-import ()
+import (
+	"errors"
+	"time"
+)
 
 type GetDepositAddressSettings struct {
 	CoinName string `json:"coin_name"` // ticker (example: BTC)
@@ -13,6 +16,20 @@ type WithdrawCoinsToAddressSettings struct {
 	Address  string  `json:"address"`   // destination address
 }
 
+// Validate checks that all the fields required by WithdrawCoinsToAddress are set
+func (s *WithdrawCoinsToAddressSettings) Validate() error {
+	if s.CoinName == "" {
+		return errors.New("WithdrawCoinsToAddress: coin name hasn't been set")
+	}
+	if s.Amount <= 0 {
+		return errors.New("WithdrawCoinsToAddress: amount must be positive")
+	}
+	if s.Address == "" {
+		return errors.New("WithdrawCoinsToAddress: address hasn't been set")
+	}
+	return nil
+}
+
 type GetDepositAddress struct {
 	Success uint8     `json:"success"`
 	Return  GDAReturn `json:"return"`
@@ -20,9 +37,9 @@ type GetDepositAddress struct {
 }
 
 type GDAReturn struct {
-	Address         string  `json:"address"`
-	ProcessedAmount float64 `json:"processed_amount"`
-	ServerTime      uint64  `json:"server_time"`
+	Address         string    `json:"address"`
+	ProcessedAmount float64   `json:"processed_amount"`
+	ServerTime      time.Time `json:"server_time"`
 }
 
 func NewGetDepositAddress() GetDepositAddress {
@@ -38,7 +55,7 @@ type WithdrawCoinsToAddress struct {
 }
 
 type WCTAReturn struct {
-	ServerTime uint64 `json:"server_time"`
+	ServerTime time.Time `json:"server_time"`
 }
 
 func NewWithdrawCoinsToAddress() WithdrawCoinsToAddress {
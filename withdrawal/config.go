@@ -0,0 +1,96 @@
+package withdrawal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// Duration is a time.Duration read from JSON strings like "72h"
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+
+	return nil
+}
+
+// MarshalJSON formats the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Address is an allowed destination address
+type Address struct {
+	Address string    `json:"address"` // destination address
+	Label   string    `json:"label"`   // owner or purpose of the address
+	Added   time.Time `json:"added"`   // time the address was added, starts the cooling-off period (required)
+}
+
+// Limit caps withdrawals of a coin
+type Limit struct {
+	PerTransaction float64 `json:"per_transaction"` // maximal amount of one withdrawal (0 - no cap)
+	Daily          float64 `json:"daily"`           // maximal amount in any 24 hours (0 - no cap)
+}
+
+// Config is the withdrawal policy
+type Config struct {
+	Addresses  map[string][]Address `json:"addresses"`   // allowed addresses by coin (example: btc)
+	Limits     map[string]Limit     `json:"limits"`      // limits by coin
+	CoolingOff Duration             `json:"cooling_off"` // time before a newly added address may be used
+	TwoStep    bool                 `json:"two_step"`    // require Prepare and Confirm
+	TokenTTL   Duration             `json:"token_ttl"`   // lifetime of a confirmation token (on default: 10m)
+	StateFile  string               `json:"state_file"`  // file keeping recent withdrawals for daily caps between restarts
+}
+
+// LoadConfig reads the policy from a JSON file
+func LoadConfig(path string) (Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var config Config
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return Config{}, err
+	}
+
+	return config, nil
+}
+
+// normalize lowers coin names, as coins are lower case in the Yobit API, and checks
+// that every address has the time it was added, so the cooling-off can't be skipped
+func (c *Config) normalize() error {
+	addresses := make(map[string][]Address, len(c.Addresses))
+	for coin, list := range c.Addresses {
+		for _, a := range list {
+			if a.Added.IsZero() {
+				return fmt.Errorf("withdrawal: %s address %s has no added time", coin, a.Address)
+			}
+		}
+		addresses[strings.ToLower(coin)] = append(addresses[strings.ToLower(coin)], list...)
+	}
+	c.Addresses = addresses
+
+	limits := make(map[string]Limit, len(c.Limits))
+	for coin, limit := range c.Limits {
+		limits[strings.ToLower(coin)] = limit
+	}
+	c.Limits = limits
+
+	return nil
+}
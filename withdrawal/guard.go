@@ -0,0 +1,261 @@
+// Package withdrawal guards WithdrawCoinsToAddress with an address allowlist,
+// amount caps, a cooling-off period for new addresses and an optional
+// prepare/confirm flow with one-time tokens.
+package withdrawal
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+var (
+	ErrNotAllowed     = errors.New("withdrawal: address is not in the allowlist")
+	ErrCoolingOff     = errors.New("withdrawal: address is in the cooling-off period")
+	ErrLimitExceeded  = errors.New("withdrawal: limit exceeded")
+	ErrInvalidToken   = errors.New("withdrawal: invalid or expired confirmation token")
+	ErrTwoStepEnabled = errors.New("withdrawal: two-step confirmation is required")
+)
+
+// defaultTokenTTL is the lifetime of a confirmation token if the config has none
+const defaultTokenTTL = 10 * time.Minute
+
+// Withdrawer sends withdrawals (e.g. *api.TradeAPI)
+type Withdrawer interface {
+	WithdrawCoinsToAddress(t *api.WithdrawCoinsToAddressSettings) (api.WithdrawCoinsToAddress, error)
+}
+
+// spend is a withdrawal counted against the daily cap
+type spend struct {
+	Coin   string    `json:"coin"`
+	Amount float64   `json:"amount"`
+	Time   time.Time `json:"time"`
+}
+
+// pending is a prepared withdrawal waiting for confirmation
+type pending struct {
+	settings api.WithdrawCoinsToAddressSettings
+	expires  time.Time
+}
+
+// Guard checks withdrawals against the policy before sending them
+type Guard struct {
+	withdrawer Withdrawer
+	config     Config
+
+	mu      sync.Mutex
+	spends  []spend
+	pending map[string]pending
+
+	// now returns the current time
+	now func() time.Time
+}
+
+// NewGuard is a constructor for the Guard. Recent withdrawals are loaded from the state file, if set.
+func NewGuard(withdrawer Withdrawer, config Config) (*Guard, error) {
+	err := config.normalize()
+	if err != nil {
+		return nil, err
+	}
+	g := &Guard{
+		withdrawer: withdrawer,
+		config:     config,
+		pending:    make(map[string]pending),
+		now:        time.Now,
+	}
+
+	if config.StateFile != "" {
+		data, err := ioutil.ReadFile(config.StateFile)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if len(data) > 0 {
+			err = json.Unmarshal(data, &g.spends)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return g, nil
+}
+
+// Check returns an error if the withdrawal breaks the policy
+func (g *Guard) Check(t *api.WithdrawCoinsToAddressSettings) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.check(t)
+}
+
+// Withdraw checks and sends the withdrawal. It fails if two-step confirmation is enabled.
+func (g *Guard) Withdraw(t *api.WithdrawCoinsToAddressSettings) (api.WithdrawCoinsToAddress, error) {
+	if g.config.TwoStep {
+		return api.WithdrawCoinsToAddress{}, ErrTwoStepEnabled
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	err := g.check(t)
+	if err != nil {
+		return api.WithdrawCoinsToAddress{}, err
+	}
+
+	return g.send(*t)
+}
+
+// Prepare checks the withdrawal and returns a one-time token for Confirm
+func (g *Guard) Prepare(t *api.WithdrawCoinsToAddressSettings) (string, time.Time, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	err := g.check(t)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	b := make([]byte, 16)
+	_, err = rand.Read(b)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	token := hex.EncodeToString(b)
+
+	ttl := time.Duration(g.config.TokenTTL)
+	if ttl == 0 {
+		ttl = defaultTokenTTL
+	}
+	expires := g.now().Add(ttl)
+	g.pending[token] = pending{settings: *t, expires: expires}
+
+	return token, expires, nil
+}
+
+// Confirm sends a prepared withdrawal. The token is consumed even if sending fails.
+func (g *Guard) Confirm(token string) (api.WithdrawCoinsToAddress, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p, ok := g.pending[token]
+	delete(g.pending, token)
+	if !ok || g.now().After(p.expires) {
+		return api.WithdrawCoinsToAddress{}, ErrInvalidToken
+	}
+
+	// limits may have been used by other withdrawals since Prepare
+	err := g.check(&p.settings)
+	if err != nil {
+		return api.WithdrawCoinsToAddress{}, err
+	}
+
+	return g.send(p.settings)
+}
+
+// Cancel drops a prepared withdrawal
+func (g *Guard) Cancel(token string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	delete(g.pending, token)
+}
+
+// check validates the withdrawal against the policy
+func (g *Guard) check(t *api.WithdrawCoinsToAddressSettings) error {
+	err := t.Validate()
+	if err != nil {
+		return err
+	}
+	coin := strings.ToLower(t.CoinName)
+	now := g.now()
+
+	var allowed *Address
+	for i, a := range g.config.Addresses[coin] {
+		if a.Address == t.Address {
+			allowed = &g.config.Addresses[coin][i]
+			break
+		}
+	}
+	if allowed == nil {
+		return fmt.Errorf("%w: %s %s", ErrNotAllowed, coin, t.Address)
+	}
+	if until := allowed.Added.Add(time.Duration(g.config.CoolingOff)); now.Before(until) {
+		return fmt.Errorf("%w: %s can be used after %s", ErrCoolingOff, t.Address, until.Format(time.RFC3339))
+	}
+
+	limit := g.config.Limits[coin]
+	if limit.PerTransaction > 0 && t.Amount > limit.PerTransaction {
+		return fmt.Errorf("%w: %v %s is above the per-transaction cap of %v", ErrLimitExceeded, t.Amount, coin, limit.PerTransaction)
+	}
+	if limit.Daily > 0 {
+		spent := g.spent(coin, now)
+		if spent+t.Amount > limit.Daily {
+			return fmt.Errorf("%w: %v %s withdrawn in 24 hours, daily cap is %v", ErrLimitExceeded, spent, coin, limit.Daily)
+		}
+	}
+
+	return nil
+}
+
+// spent returns the amount of coin withdrawn in the 24 hours before now
+func (g *Guard) spent(coin string, now time.Time) float64 {
+	var total float64
+	for _, s := range g.spends {
+		if s.Coin == coin && now.Sub(s.Time) < 24*time.Hour {
+			total += s.Amount
+		}
+	}
+	return total
+}
+
+// send records the withdrawal for the daily cap and sends it. The amount is recorded
+// before sending and kept unless the API definitely rejected the withdrawal, so a
+// timeout or a transport error can't let retries exceed the cap.
+func (g *Guard) send(t api.WithdrawCoinsToAddressSettings) (api.WithdrawCoinsToAddress, error) {
+	now := g.now()
+	var recent []spend
+	for _, s := range g.spends {
+		if now.Sub(s.Time) < 24*time.Hour {
+			recent = append(recent, s)
+		}
+	}
+	g.spends = append(recent, spend{Coin: strings.ToLower(t.CoinName), Amount: t.Amount, Time: now})
+	err := g.saveState()
+	if err != nil {
+		g.spends = recent
+		return api.WithdrawCoinsToAddress{}, err
+	}
+
+	resp, err := g.withdrawer.WithdrawCoinsToAddress(&t)
+	if err == nil && resp.Success == 0 {
+		// a failed save only leaves the rejected amount counted
+		g.spends = recent
+		g.saveState()
+		return resp, fmt.Errorf("withdrawal: %s", resp.Error)
+	}
+
+	return resp, err
+}
+
+// saveState writes recent withdrawals to the state file
+func (g *Guard) saveState() error {
+	if g.config.StateFile == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(g.spends)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(g.config.StateFile, data, 0600)
+}
@@ -0,0 +1,176 @@
+package withdrawal
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// fakeWithdrawer returns the results in order, then success
+type fakeWithdrawer struct {
+	results []error // nil - success, errRejected - Success 0, others - transport errors
+	sent    int
+}
+
+var errRejected = errors.New("rejected")
+
+func (f *fakeWithdrawer) WithdrawCoinsToAddress(t *api.WithdrawCoinsToAddressSettings) (api.WithdrawCoinsToAddress, error) {
+	f.sent++
+	var err error
+	if len(f.results) > 0 {
+		err, f.results = f.results[0], f.results[1:]
+	}
+	switch err {
+	case nil:
+		return api.WithdrawCoinsToAddress{Success: 1}, nil
+	case errRejected:
+		return api.WithdrawCoinsToAddress{Success: 0, Error: "Insufficient funds"}, nil
+	}
+	return api.WithdrawCoinsToAddress{}, err
+}
+
+var start = time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+func testConfig() Config {
+	return Config{
+		Addresses: map[string][]Address{
+			"BTC": {
+				{Address: "old", Added: start.Add(-72 * time.Hour)},
+				{Address: "new", Added: start.Add(-time.Hour)},
+			},
+		},
+		Limits:     map[string]Limit{"btc": {PerTransaction: 1, Daily: 2}},
+		CoolingOff: Duration(24 * time.Hour),
+	}
+}
+
+func withdrawal(address string, amount float64) *api.WithdrawCoinsToAddressSettings {
+	return &api.WithdrawCoinsToAddressSettings{CoinName: "BTC", Amount: amount, Address: address}
+}
+
+func TestGuardCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		t    *api.WithdrawCoinsToAddressSettings
+		err  error
+	}{
+		{"allowed", withdrawal("old", 1), nil},
+		{"unknown address", withdrawal("other", 1), ErrNotAllowed},
+		{"cooling off", withdrawal("new", 1), ErrCoolingOff},
+		{"per-transaction cap", withdrawal("old", 1.5), ErrLimitExceeded},
+	}
+
+	g, err := NewGuard(&fakeWithdrawer{}, testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.now = func() time.Time { return start }
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := g.Check(tt.t); !errors.Is(err, tt.err) {
+				t.Fatalf("Check = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestNewGuardRejectsZeroAdded(t *testing.T) {
+	config := testConfig()
+	config.Addresses["btc"] = []Address{{Address: "undated"}}
+
+	if _, err := NewGuard(&fakeWithdrawer{}, config); err == nil {
+		t.Fatal("NewGuard accepted an address without the added time")
+	}
+}
+
+func TestGuardDailyCap(t *testing.T) {
+	timeout := errors.New("timeout")
+
+	tests := []struct {
+		name    string
+		results []error
+		allowed int // withdrawals of 1 btc sent before the cap stops them
+	}{
+		{"successes count", nil, 2},
+		{"rejections don't count", []error{errRejected, errRejected}, 4},
+		{"transport errors count", []error{timeout, timeout}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig()
+			config.StateFile = filepath.Join(t.TempDir(), "state.json")
+			withdrawer := &fakeWithdrawer{results: tt.results}
+			g, err := NewGuard(withdrawer, config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.now = func() time.Time { return start }
+
+			for i := 0; i < 5; i++ {
+				_, err := g.Withdraw(withdrawal("old", 1))
+				if errors.Is(err, ErrLimitExceeded) {
+					break
+				}
+			}
+			if withdrawer.sent != tt.allowed {
+				t.Fatalf("sent = %d, want %d", withdrawer.sent, tt.allowed)
+			}
+
+			// the spends survive a restart
+			restarted, err := NewGuard(withdrawer, config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			restarted.now = func() time.Time { return start }
+			if err := restarted.Check(withdrawal("old", 1)); !errors.Is(err, ErrLimitExceeded) {
+				t.Fatalf("Check after restart = %v, want ErrLimitExceeded", err)
+			}
+			restarted.now = func() time.Time { return start.Add(24 * time.Hour) }
+			if err := restarted.Check(withdrawal("old", 1)); err != nil {
+				t.Fatalf("Check a day later = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestGuardTwoStep(t *testing.T) {
+	config := testConfig()
+	config.TwoStep = true
+	withdrawer := &fakeWithdrawer{}
+	g, err := NewGuard(withdrawer, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.now = func() time.Time { return start }
+
+	if _, err := g.Withdraw(withdrawal("old", 1)); err != ErrTwoStepEnabled {
+		t.Fatalf("Withdraw = %v, want ErrTwoStepEnabled", err)
+	}
+	token, _, err := g.Prepare(withdrawal("old", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Confirm(token); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Confirm(token); err != ErrInvalidToken {
+		t.Fatalf("second Confirm = %v, want ErrInvalidToken", err)
+	}
+
+	token, _, err = g.Prepare(withdrawal("old", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.now = func() time.Time { return start.Add(time.Hour) }
+	if _, err := g.Confirm(token); err != ErrInvalidToken {
+		t.Fatalf("expired Confirm = %v, want ErrInvalidToken", err)
+	}
+	if withdrawer.sent != 1 {
+		t.Fatalf("sent = %d, want 1", withdrawer.sent)
+	}
+}
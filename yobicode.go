@@ -19,7 +19,7 @@ type CreateYobicode struct {
 
 type CYReturn struct {
 	Coupon  string             `json:"coupon"`  // Yobicode
-	TransID uint8              `json:"transID"` // always 1 for compatibility with api of other exchanges
+	TransID uint64             `json:"transID"` // always 1 for compatibility with api of other exchanges
 	Funds   map[string]float64 `json:"funds"`   // balances active after request
 }
 
@@ -38,7 +38,7 @@ type RedeemYobicode struct {
 type RYReturn struct {
 	CouponAmount   float64            `json:"couponAmount"`   // The amount that has been redeemed.
 	CouponCurrency string             `json:"couponCurrency"` // The currency of the yobicode that has been redeemed.
-	TransID        uint8              `json:"transID"`        // always 1 for compatibility with api of other exchanges
+	TransID        uint64             `json:"transID"`        // always 1 for compatibility with api of other exchanges
 	Funds          map[string]float64 `json:"funds"`          // balances active after request
 }
 
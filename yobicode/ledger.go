@@ -0,0 +1,297 @@
+// Package yobicode keeps a ledger of created and redeemed Yobicodes (coupons).
+// Coupon strings are bearer funds, so they are stored encrypted with AES-GCM.
+package yobicode
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+// ErrInvalidCoupon is returned for coupons of a wrong format
+var ErrInvalidCoupon = errors.New("yobicode: invalid coupon format")
+
+// Trader creates and redeems Yobicodes (e.g. *api.TradeAPI)
+type Trader interface {
+	CreateYobicode(t *api.CreateYobicodeSettings) (api.CreateYobicode, error)
+	RedeemYobicode(t *api.RedeemYobicodeSettings) (api.RedeemYobicode, error)
+}
+
+// Entry is a Yobicode known to the ledger
+type Entry struct {
+	ID         string    `json:"id"`          // hash of the coupon, used to look coupons up
+	Coupon     string    `json:"coupon"`      // encrypted coupon
+	Currency   string    `json:"currency"`    // coin of the coupon
+	Amount     float64   `json:"amount"`      // coupon amount
+	Label      string    `json:"label"`       // caller label (example: whom the code was issued to)
+	Created    time.Time `json:"created"`     // creation time, zero for coupons created elsewhere
+	Redeemed   bool      `json:"redeemed"`    // redeemed through this ledger
+	RedeemedAt time.Time `json:"redeemed_at"` // redemption time
+}
+
+// ValidateCoupon checks the coupon format (example: YOBITUZ0HHSTB...OQX3H01BTC)
+func ValidateCoupon(coupon string) error {
+	if !strings.HasPrefix(coupon, "YOBIT") || len(coupon) < 20 || len(coupon) > 128 {
+		return ErrInvalidCoupon
+	}
+	for _, c := range coupon {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return ErrInvalidCoupon
+		}
+	}
+	return nil
+}
+
+// Ledger records Yobicodes in a JSON file
+type Ledger struct {
+	trader Trader
+	path   string
+	aead   cipher.AEAD
+	macKey []byte
+
+	mu      sync.Mutex
+	entries map[string]*Entry
+}
+
+// Open opens the ledger file, creating it on first save. The key must be 32 bytes long.
+func Open(trader Trader, path string, key []byte) (*Ledger, error) {
+	if len(key) != 32 {
+		return nil, errors.New("yobicode: key must be 32 bytes long")
+	}
+
+	// separate keys for encryption and lookup hashes
+	encKey := hmac.New(sha256.New, key)
+	encKey.Write([]byte("encrypt"))
+	macKey := hmac.New(sha256.New, key)
+	macKey.Write([]byte("lookup"))
+
+	block, err := aes.NewCipher(encKey.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Ledger{
+		trader:  trader,
+		path:    path,
+		aead:    aead,
+		macKey:  macKey.Sum(nil),
+		entries: make(map[string]*Entry),
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(data) > 0 {
+		var entries []Entry
+		err = json.Unmarshal(data, &entries)
+		if err != nil {
+			return nil, err
+		}
+		for i := range entries {
+			l.entries[entries[i].ID] = &entries[i]
+		}
+	}
+
+	return l, nil
+}
+
+// Create creates a Yobicode and records it with the label. The coupon is returned
+// even if saving the ledger fails, together with the error.
+func (l *Ledger) Create(currency string, amount float64, label string) (string, Entry, error) {
+	resp, err := l.trader.CreateYobicode(&api.CreateYobicodeSettings{Currency: currency, Amount: amount})
+	if err != nil {
+		return "", Entry{}, err
+	}
+	if resp.Success == 0 {
+		return "", Entry{}, fmt.Errorf("yobicode: create: %s", resp.Error)
+	}
+	coupon := resp.Return.Coupon
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry := &Entry{
+		ID:       l.lookup(coupon),
+		Currency: strings.ToLower(currency),
+		Amount:   amount,
+		Label:    label,
+		Created:  time.Now(),
+	}
+	entry.Coupon, err = l.encrypt(coupon, entry)
+	if err != nil {
+		return coupon, Entry{}, err
+	}
+	l.entries[entry.ID] = entry
+
+	return coupon, *entry, l.save()
+}
+
+// Redeem validates and redeems the coupon and marks it redeemed. Coupons created
+// elsewhere are added to the ledger as redeemed.
+func (l *Ledger) Redeem(coupon, label string) (api.RedeemYobicode, error) {
+	err := ValidateCoupon(coupon)
+	if err != nil {
+		return api.RedeemYobicode{}, err
+	}
+
+	resp, err := l.trader.RedeemYobicode(&api.RedeemYobicodeSettings{Coupon: coupon})
+	if err != nil {
+		return resp, err
+	}
+	if resp.Success == 0 {
+		return resp, fmt.Errorf("yobicode: redeem: %s", resp.Error)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	id := l.lookup(coupon)
+	entry, ok := l.entries[id]
+	if !ok {
+		entry = &Entry{
+			ID:       id,
+			Currency: strings.ToLower(resp.Return.CouponCurrency),
+			Amount:   resp.Return.CouponAmount,
+			Label:    label,
+		}
+		entry.Coupon, err = l.encrypt(coupon, entry)
+		if err != nil {
+			return resp, err
+		}
+		l.entries[id] = entry
+	}
+	entry.Redeemed = true
+	entry.RedeemedAt = time.Now()
+
+	return resp, l.save()
+}
+
+// Entries returns all entries ordered by creation time, with coupons encrypted
+func (l *Ledger) Entries() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := make([]Entry, 0, len(l.entries))
+	for _, e := range l.entries {
+		entries = append(entries, *e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Created.Before(entries[j].Created)
+	})
+
+	return entries
+}
+
+// Coupon returns the decrypted coupon of the entry
+func (l *Ledger) Coupon(id string) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.entries[id]
+	if !ok {
+		return "", fmt.Errorf("yobicode: entry %s not found", id)
+	}
+
+	return l.decrypt(entry)
+}
+
+// lookup returns the keyed hash of the coupon
+func (l *Ledger) lookup(coupon string) string {
+	mac := hmac.New(sha256.New, l.macKey)
+	mac.Write([]byte(coupon))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// additional returns the authenticated data of the entry, so an encrypted coupon
+// can't be moved to another entry or have its coin and amount changed
+func additional(e *Entry) []byte {
+	return []byte(e.ID + "|" + e.Currency + "|" + strconv.FormatFloat(e.Amount, 'g', -1, 64))
+}
+
+// encrypt returns the encrypted coupon of the entry
+func (l *Ledger) encrypt(coupon string, e *Entry) (string, error) {
+	nonce := make([]byte, l.aead.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return "", err
+	}
+
+	sealed := l.aead.Seal(nonce, nonce, []byte(coupon), additional(e))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt returns the coupon of the entry
+func (l *Ledger) decrypt(e *Entry) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(e.Coupon)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < l.aead.NonceSize() {
+		return "", errors.New("yobicode: encrypted coupon is too short")
+	}
+
+	nonce, sealed := data[:l.aead.NonceSize()], data[l.aead.NonceSize():]
+	coupon, err := l.aead.Open(nil, nonce, sealed, additional(e))
+	if err != nil {
+		return "", err
+	}
+
+	return string(coupon), nil
+}
+
+// save writes all entries to a temporary file and moves it over the ledger
+func (l *Ledger) save() error {
+	entries := make([]Entry, 0, len(l.entries))
+	for _, e := range l.entries {
+		entries = append(entries, *e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID < entries[j].ID
+	})
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(l.path), filepath.Base(l.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), l.path)
+}
@@ -0,0 +1,97 @@
+package yobicode
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	api "github.com/vladivolo/yobit-api"
+)
+
+type fakeTrader struct {
+	coupons []string
+}
+
+func (f *fakeTrader) CreateYobicode(t *api.CreateYobicodeSettings) (api.CreateYobicode, error) {
+	resp := api.CreateYobicode{Success: 1}
+	resp.Return.Coupon, f.coupons = f.coupons[0], f.coupons[1:]
+	return resp, nil
+}
+
+func (f *fakeTrader) RedeemYobicode(t *api.RedeemYobicodeSettings) (api.RedeemYobicode, error) {
+	return api.RedeemYobicode{Success: 1}, nil
+}
+
+func TestLedgerTampering(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	trader := &fakeTrader{coupons: []string{"YOBITAAAAAAAAAAAAAAAAAAAABTC", "YOBITBBBBBBBBBBBBBBBBBBBBBTC"}}
+
+	l, err := Open(trader, path, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, small, err := l.Create("btc", 0.001, "small")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, large, err := l.Create("btc", 1, "large")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(entries []Entry)
+		ok     bool
+	}{
+		{"untouched", func([]Entry) {}, true},
+		{"coupon moved to another entry", func(entries []Entry) {
+			for i := range entries {
+				if entries[i].ID == small.ID {
+					entries[i].Coupon = large.Coupon
+				}
+			}
+		}, false},
+		{"amount changed", func(entries []Entry) {
+			for i := range entries {
+				if entries[i].ID == small.ID {
+					entries[i].Amount = 1
+				}
+			}
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var entries []Entry
+			if err := json.Unmarshal(data, &entries); err != nil {
+				t.Fatal(err)
+			}
+			tt.tamper(entries)
+			data, _ = json.Marshal(entries)
+			tampered := filepath.Join(t.TempDir(), "ledger.json")
+			if err := ioutil.WriteFile(tampered, data, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			l, err := Open(trader, tampered, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			coupon, err := l.Coupon(small.ID)
+			if (err == nil) != tt.ok {
+				t.Fatalf("Coupon = %q, %v, want ok %v", coupon, err, tt.ok)
+			}
+			if tt.ok && coupon != "YOBITAAAAAAAAAAAAAAAAAAAABTC" {
+				t.Fatalf("Coupon = %q", coupon)
+			}
+		})
+	}
+}